@@ -84,6 +84,39 @@ func (s *gatewayServer) ValidateBatch(stream pb.CoordinateValidator_ValidateBatc
 	return client.ValidateBatch(stream.Context(), stream)
 }
 
+func (s *gatewayServer) GetTrajectory(ctx context.Context, req *pb.TrajectoryRequest) (*pb.TrajectoryResponse, error) {
+	conn, err := grpc.Dial(s.refinementAddr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	client := pb.NewCoordinateValidatorClient(conn)
+	return client.GetTrajectory(ctx, req)
+}
+
+func (s *gatewayServer) GetClockOffset(ctx context.Context, req *pb.ClockOffsetRequest) (*pb.ClockOffsetResponse, error) {
+	conn, err := grpc.Dial(s.refinementAddr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	client := pb.NewCoordinateValidatorClient(conn)
+	return client.GetClockOffset(ctx, req)
+}
+
+func (s *gatewayServer) ListClonedDevices(ctx context.Context, req *pb.ClonedDevicesRequest) (*pb.ClonedDevicesResponse, error) {
+	conn, err := grpc.Dial(s.refinementAddr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	client := pb.NewCoordinateValidatorClient(conn)
+	return client.ListClonedDevices(ctx, req)
+}
+
 // ============================================
 // Learning API Routing
 // ============================================
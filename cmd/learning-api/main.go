@@ -84,7 +84,9 @@ func (s *learningServer) LearnFromCoordinates(ctx context.Context, req *pb.Learn
 		Longitude: req.Longitude,
 		Accuracy:  req.Accuracy,
 		Timestamp: req.Timestamp,
-		// TODO: convert Wifi, Bluetooth, CellTowers
+		Wifi:       convertWifi(req.Wifi),
+		Bluetooth:  convertBT(req.Bluetooth),
+		CellTowers: convertCell(req.CellTowers),
 	}
 
 	resp, err := s.learningCore.Learn(ctx, modelReq)
@@ -100,7 +102,7 @@ func (s *learningServer) LearnFromCoordinates(ctx context.Context, req *pb.Learn
 }
 
 func (s *learningServer) GetCompanionSources(ctx context.Context, req *pb.GetCompanionsRequest) (*pb.GetCompanionsResponse, error) {
-	companions, err := s.cache.GetCompanions(ctx, req.ObjectId)
+	companions, err := s.learningCore.Companions(ctx, req.ObjectId, modelPointType(req.PointType))
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +117,7 @@ func (s *learningServer) GetCompanionSources(ctx context.Context, req *pb.GetCom
 			IsStationary:  c.IsStationary,
 			FirstSeen:     c.FirstSeen,
 			LastSeen:      c.LastSeen,
+			Places:        c.Places,
 		}
 	}
 
@@ -125,6 +128,54 @@ func (s *learningServer) GetCompanionSources(ctx context.Context, req *pb.GetCom
 // Converters
 // ============================================
 
+func convertWifi(wifi []*pb.WifiAccessPoint) []model.WifiAP {
+	result := make([]model.WifiAP, 0, len(wifi))
+	for _, w := range wifi {
+		result = append(result, model.WifiAP{
+			SSID:      w.Ssid,
+			BSSID:     w.Bssid,
+			RSSI:      w.Rssi,
+			EID:       w.Eid,
+			Frequency: w.Frequency,
+		})
+	}
+	return result
+}
+
+func convertBT(bt []*pb.BluetoothDevice) []model.BluetoothDev {
+	result := make([]model.BluetoothDev, 0, len(bt))
+	for _, b := range bt {
+		result = append(result, model.BluetoothDev{
+			MAC:  b.Mac,
+			RSSI: b.Rssi,
+			EID:  b.Eid,
+		})
+	}
+	return result
+}
+
+func convertCell(cells []*pb.CellTower) []model.CellTower {
+	result := make([]model.CellTower, 0, len(cells))
+	for _, c := range cells {
+		cell := model.CellTower{
+			MCC:           c.Mcc,
+			MNC:           c.Mnc,
+			LAC:           c.Lac,
+			CellID:        c.CellId,
+			PCI:           c.Pci,
+			EARFCN:        c.Earfcn,
+			TimingAdvance: c.TimingAdvance,
+			RSSI:          c.Rssi,
+			EID:           c.Eid,
+		}
+		if c.Radio != pb.RadioType_RADIO_TYPE_UNSPECIFIED {
+			cell.Radio = model.RadioType(c.Radio.String())
+		}
+		result = append(result, cell)
+	}
+	return result
+}
+
 func convertLearningResult(r model.LearningResult) pb.LearningResult {
 	switch r {
 	case model.LearningResultLeared:
@@ -152,3 +203,17 @@ func convertPointType(pt model.PointType) pb.PointType {
 		return pb.PointType_POINT_TYPE_UNSPECIFIED
 	}
 }
+
+// modelPointType converts a proto point type; unspecified means any.
+func modelPointType(pt pb.PointType) model.PointType {
+	switch pt {
+	case pb.PointType_WIFI:
+		return model.PointTypeWifi
+	case pb.PointType_CELL:
+		return model.PointTypeCell
+	case pb.PointType_BLE:
+		return model.PointTypeBT
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,95 @@
+// Command migrate-cells rewrites cells stored under the old cell:{cell_id}:{lac}
+// Redis keys to their global cell:{radio}:{mcc}:{mnc}:{lac}:{cell_id} keys.
+//
+// Old entries only carry the cell ID and LAC in the key. MCC, MNC and radio
+// are taken from the stored value when present, otherwise from the flags.
+// Entries whose operator or radio can't be determined are left in place.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"coordinate-validator/internal/cache"
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+func main() {
+	radio := flag.String("radio", "", "radio type for entries without one (GSM, UMTS, CDMA, LTE, NR)")
+	mcc := flag.Uint("mcc", 0, "MCC for entries without one")
+	mnc := flag.Int("mnc", -1, "MNC for entries without an MCC")
+	dryRun := flag.Bool("dry-run", false, "report what would be migrated without writing")
+	flag.Parse()
+
+	defaultRadio, err := parseRadio(*radio)
+	if err != nil {
+		log.Fatalf("Invalid -radio: %v", err)
+	}
+
+	cfg := config.Load()
+
+	redisCache, err := cache.NewRedisCache(&cfg.Redis)
+	if err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	defer redisCache.Close()
+
+	ctx := context.Background()
+	var migrated, kept, skipped int
+
+	err = redisCache.ScanLegacyCells(ctx, func(legacy cache.LegacyCell) error {
+		cell := legacy.Cell
+		if cell.MCC == 0 {
+			if *mcc == 0 || *mnc < 0 {
+				log.Printf("Skipping %s: unknown operator (set -mcc and -mnc)", legacy.Key)
+				skipped++
+				return nil
+			}
+			cell.MCC = uint32(*mcc)
+			cell.MNC = uint32(*mnc)
+		}
+		if cell.Radio == model.RadioUnknown {
+			if defaultRadio == model.RadioUnknown {
+				log.Printf("Skipping %s: unknown radio (set -radio)", legacy.Key)
+				skipped++
+				return nil
+			}
+			cell.Radio = defaultRadio
+		}
+
+		if *dryRun {
+			log.Printf("%s -> cell:%s", legacy.Key, cell.Key())
+			migrated++
+			return nil
+		}
+
+		written, err := redisCache.MoveLegacyCell(ctx, legacy.Key, &cell)
+		if err != nil {
+			return err
+		}
+		if written {
+			migrated++
+		} else {
+			kept++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	log.Printf("Migrated %d cells, %d merged into existing entries, %d skipped", migrated, kept, skipped)
+}
+
+// parseRadio parses the -radio flag. An empty value leaves the radio unknown.
+func parseRadio(s string) (model.RadioType, error) {
+	switch r := model.RadioType(strings.ToUpper(s)); r {
+	case model.RadioUnknown, model.RadioGSM, model.RadioUMTS, model.RadioCDMA, model.RadioLTE, model.RadioNR:
+		return r, nil
+	}
+	return model.RadioUnknown, fmt.Errorf("unknown radio type %q", s)
+}
@@ -13,6 +13,8 @@ import (
 	"coordinate-validator/internal/cache"
 	"coordinate-validator/internal/config"
 	"coordinate-validator/internal/core"
+	"coordinate-validator/internal/model"
+	"coordinate-validator/internal/queue"
 	pb "coordinate-validator/pkg/pb"
 )
 
@@ -37,6 +39,11 @@ func main() {
 	// Create validation core
 	validationCore := core.NewValidationCore(redisCache, &cfg.Validation)
 
+	// Publish spoofing and cloned device alerts
+	alerts := queue.NewKafkaProducer(&cfg.Kafka)
+	defer alerts.Close()
+	validationCore.SetAlertPublisher(alerts)
+
 	// Create gRPC server
 	lis, err := net.Listen("tcp", ":"+cfg.Server.Port)
 	if err != nil {
@@ -78,6 +85,7 @@ func (s *refinementServer) Validate(ctx context.Context, req *pb.CoordinateReque
 		Wifi:       convertWifi(req.Wifi),
 		Bluetooth:  convertBT(req.Bluetooth),
 		CellTowers: convertCell(req.CellTowers),
+		GNSS:       convertGNSS(req.Gnss),
 	}
 
 	// Validate
@@ -86,15 +94,23 @@ func (s *refinementServer) Validate(ctx context.Context, req *pb.CoordinateReque
 		return nil, err
 	}
 
-	// Update device position for speed check
-	s.validator.UpdateDevicePosition(ctx, modelReq)
+	// Only accepted points enter the trajectory window
+	if resp.Result != model.ValidationResultInvalid {
+		s.validator.UpdateDevicePosition(ctx, modelReq)
+	}
 
 	// Convert to proto
 	return &pb.CoordinateResponse{
-		Result:            convertValidationResult(resp.Result),
-		Confidence:        resp.Confidence,
-		EstimatedAccuracy: resp.EstimatedAccuracy,
-		Reason:            resp.Reason,
+		Result:             convertValidationResult(resp.Result),
+		Confidence:         resp.Confidence,
+		EstimatedAccuracy:  resp.EstimatedAccuracy,
+		Reason:             resp.Reason,
+		Estimate:           convertEstimate(resp.Estimate),
+		CorrectedLatitude:  resp.CorrectedLatitude,
+		CorrectedLongitude: resp.CorrectedLongitude,
+		AnomalyScore:       resp.AnomalyScore,
+		SpoofingScore:      resp.SpoofingScore,
+		Checks:             convertChecks(resp.Checks),
 	}, nil
 }
 
@@ -114,6 +130,7 @@ func (s *refinementServer) ValidateBatch(stream pb.CoordinateValidator_ValidateB
 			Wifi:       convertWifi(req.Wifi),
 			Bluetooth:  convertBT(req.Bluetooth),
 			CellTowers: convertCell(req.CellTowers),
+			GNSS:       convertGNSS(req.Gnss),
 		}
 
 		resp, err := s.validator.Validate(stream.Context(), modelReq)
@@ -121,13 +138,21 @@ func (s *refinementServer) ValidateBatch(stream pb.CoordinateValidator_ValidateB
 			return err
 		}
 
-		s.validator.UpdateDevicePosition(stream.Context(), modelReq)
+		if resp.Result != model.ValidationResultInvalid {
+			s.validator.UpdateDevicePosition(stream.Context(), modelReq)
+		}
 
 		pbResp := &pb.CoordinateResponse{
-			Result:            convertValidationResult(resp.Result),
-			Confidence:        resp.Confidence,
-			EstimatedAccuracy: resp.EstimatedAccuracy,
-			Reason:            resp.Reason,
+			Result:             convertValidationResult(resp.Result),
+			Confidence:         resp.Confidence,
+			EstimatedAccuracy:  resp.EstimatedAccuracy,
+			Reason:             resp.Reason,
+			Estimate:           convertEstimate(resp.Estimate),
+			CorrectedLatitude:  resp.CorrectedLatitude,
+			CorrectedLongitude: resp.CorrectedLongitude,
+			AnomalyScore:       resp.AnomalyScore,
+			SpoofingScore:      resp.SpoofingScore,
+			Checks:             convertChecks(resp.Checks),
 		}
 
 		if err := stream.Send(pbResp); err != nil {
@@ -138,26 +163,173 @@ func (s *refinementServer) ValidateBatch(stream pb.CoordinateValidator_ValidateB
 	return nil
 }
 
+func (s *refinementServer) GetTrajectory(ctx context.Context, req *pb.TrajectoryRequest) (*pb.TrajectoryResponse, error) {
+	points, err := s.validator.GetTrajectory(ctx, req.DeviceId, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	pbPoints := make([]*pb.TrackPoint, len(points))
+	for i, p := range points {
+		pbPoints[i] = &pb.TrackPoint{
+			Latitude:  p.Latitude,
+			Longitude: p.Longitude,
+			Timestamp: p.Timestamp,
+			Accuracy:  p.Accuracy,
+		}
+	}
+
+	return &pb.TrajectoryResponse{Points: pbPoints}, nil
+}
+
+func (s *refinementServer) GetClockOffset(ctx context.Context, req *pb.ClockOffsetRequest) (*pb.ClockOffsetResponse, error) {
+	skew, err := s.validator.ClockOffset(ctx, req.DeviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ClockOffsetResponse{DeviceId: req.DeviceId}
+	if skew != nil {
+		resp.OffsetSeconds = skew.OffsetSeconds
+		resp.DeviationSeconds = skew.DeviationSeconds
+		resp.Samples = skew.Samples
+		resp.Drifting = skew.Drifting
+		resp.UpdatedAt = skew.UpdatedAt
+	}
+	return resp, nil
+}
+
+func (s *refinementServer) ListClonedDevices(ctx context.Context, req *pb.ClonedDevicesRequest) (*pb.ClonedDevicesResponse, error) {
+	suspects, err := s.validator.CloneSuspects(ctx, req.DeviceId, req.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ClonedDevicesResponse{Suspects: make([]*pb.CloneSuspect, len(suspects))}
+	for i, c := range suspects {
+		resp.Suspects[i] = &pb.CloneSuspect{
+			Reason:         string(c.Reason),
+			DeviceIds:      c.DeviceIDs,
+			Count:          int32(c.Count),
+			DistanceMeters: c.Distance,
+			Latitude:       c.Latitude,
+			Longitude:      c.Longitude,
+			FirstSeen:      c.FirstSeen,
+			LastSeen:       c.LastSeen,
+		}
+	}
+	return resp, nil
+}
+
 // ============================================
 // Converters (placeholder - implement properly)
 // ============================================
 
 func convertWifi(wifi []*pb.WifiAccessPoint) []model.WifiAP {
-	// TODO: implement
-	return nil
+	result := make([]model.WifiAP, 0, len(wifi))
+	for _, w := range wifi {
+		result = append(result, model.WifiAP{
+			SSID:      w.Ssid,
+			BSSID:     w.Bssid,
+			RSSI:      w.Rssi,
+			EID:       w.Eid,
+			Frequency: w.Frequency,
+		})
+	}
+	return result
 }
 
 func convertBT(bt []*pb.BluetoothDevice) []model.BluetoothDev {
-	// TODO: implement
-	return nil
+	result := make([]model.BluetoothDev, 0, len(bt))
+	for _, b := range bt {
+		result = append(result, model.BluetoothDev{
+			MAC:  b.Mac,
+			RSSI: b.Rssi,
+			EID:  b.Eid,
+		})
+	}
+	return result
 }
 
 func convertCell(cells []*pb.CellTower) []model.CellTower {
-	// TODO: implement
-	return nil
+	result := make([]model.CellTower, 0, len(cells))
+	for _, c := range cells {
+		cell := model.CellTower{
+			MCC:           c.Mcc,
+			MNC:           c.Mnc,
+			LAC:           c.Lac,
+			CellID:        c.CellId,
+			PCI:           c.Pci,
+			EARFCN:        c.Earfcn,
+			TimingAdvance: c.TimingAdvance,
+			RSSI:          c.Rssi,
+			EID:           c.Eid,
+		}
+		if c.Radio != pb.RadioType_RADIO_TYPE_UNSPECIFIED {
+			cell.Radio = model.RadioType(c.Radio.String())
+		}
+		result = append(result, cell)
+	}
+	return result
+}
+
+func convertGNSS(g *pb.GnssInfo) *model.GNSSInfo {
+	if g == nil {
+		return nil
+	}
+	info := &model.GNSSInfo{
+		Altitude:   g.Altitude,
+		SpeedKmH:   g.SpeedKmh,
+		Course:     g.Course,
+		Satellites: g.Satellites,
+		HDOP:       g.Hdop,
+		PDOP:       g.Pdop,
+	}
+	if g.FixType != pb.FixType_FIX_TYPE_UNSPECIFIED {
+		info.FixType = model.FixType(g.FixType.String())
+	}
+	if g.Provider != pb.GnssProvider_GNSS_PROVIDER_UNSPECIFIED {
+		info.Provider = model.GNSSProvider(g.Provider.String())
+	}
+	return info
 }
 
 func convertValidationResult(r model.ValidationResult) pb.ValidationResult {
-	// TODO: implement
-	return pb.ValidationResult_VALID
+	switch r {
+	case model.ValidationResultValid:
+		return pb.ValidationResult_VALID
+	case model.ValidationResultUncertain:
+		return pb.ValidationResult_UNCERTAIN
+	default:
+		return pb.ValidationResult_INVALID
+	}
+}
+
+func convertEstimate(e *model.PositionEstimate) *pb.PositionEstimate {
+	if e == nil {
+		return nil
+	}
+	return &pb.PositionEstimate{
+		Latitude:  e.Latitude,
+		Longitude: e.Longitude,
+		Radius:    float32(e.Radius),
+		Sources:   int32(e.Sources),
+	}
+}
+
+func convertChecks(checks []model.CheckResult) []*pb.CheckResult {
+	pbChecks := make([]*pb.CheckResult, len(checks))
+	for i, c := range checks {
+		pbChecks[i] = &pb.CheckResult{
+			Check:        string(c.Check),
+			Code:         string(c.Code),
+			Passed:       c.Passed,
+			Value:        c.Value,
+			Threshold:    c.Threshold,
+			Contribution: c.Contribution,
+			Message:      c.Message,
+			Subject:      c.Subject,
+		}
+	}
+	return pbChecks
 }
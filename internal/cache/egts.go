@@ -0,0 +1,17 @@
+package cache
+
+// ============================================
+// EGTS Helpers
+// ============================================
+
+// ConvertEIDToRSSI converts the signal level of an EGTS LBS/WiFi record
+// (0-63, scaled like GSM RXLEV) to RSSI in dBm.
+func ConvertEIDToRSSI(eid int32) int32 {
+	if eid < 0 {
+		eid = 0
+	}
+	if eid > 63 {
+		eid = 63
+	}
+	return eid - 110
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -76,9 +79,15 @@ func (c *RedisCache) SetWifi(ctx context.Context, wifi *model.CachedWifi) error
 // Cell Tower Operations
 // ============================================
 
-func (c *RedisCache) GetCell(ctx context.Context, cellID uint32, lac uint32) (*model.CachedCell, error) {
-	key := fmt.Sprintf("cell:%d:%d", cellID, lac)
-	data, err := c.client.Get(ctx, key).Result()
+// Cells are keyed by their global identity: cell:{radio}:{mcc}:{mnc}:{lac}:{cell_id}.
+// Keys in the old cell:{cell_id}:{lac} format are rewritten by cmd/migrate-cells.
+
+func cellKey(key model.CellKey) string {
+	return "cell:" + key.String()
+}
+
+func (c *RedisCache) GetCell(ctx context.Context, key model.CellKey) (*model.CachedCell, error) {
+	data, err := c.client.Get(ctx, cellKey(key)).Result()
 	if err == redis.Nil {
 		return nil, nil
 	}
@@ -94,12 +103,71 @@ func (c *RedisCache) GetCell(ctx context.Context, cellID uint32, lac uint32) (*m
 }
 
 func (c *RedisCache) SetCell(ctx context.Context, cell *model.CachedCell) error {
-	key := fmt.Sprintf("cell:%d:%d", cell.CellID, cell.LAC)
 	data, err := json.Marshal(cell)
 	if err != nil {
 		return err
 	}
-	return c.client.Set(ctx, key, data, 0).Err()
+	return c.client.Set(ctx, cellKey(cell.Key()), data, 0).Err()
+}
+
+// LegacyCell is a cell stored under the old cell:{cell_id}:{lac} key.
+type LegacyCell struct {
+	Key  string
+	Cell model.CachedCell
+}
+
+// ScanLegacyCells calls fn for every cell stored under an old-format key.
+func (c *RedisCache) ScanLegacyCells(ctx context.Context, fn func(LegacyCell) error) error {
+	iter := c.client.Scan(ctx, 0, "cell:*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		parts := strings.Split(key, ":")
+		if len(parts) != 3 {
+			continue
+		}
+		cellID, err1 := strconv.ParseUint(parts[1], 10, 64)
+		lac, err2 := strconv.ParseUint(parts[2], 10, 32)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		data, err := c.client.Get(ctx, key).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		legacy := LegacyCell{Key: key}
+		if err := json.Unmarshal([]byte(data), &legacy.Cell); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		// The key is authoritative for the identity it carries
+		legacy.Cell.CellID = cellID
+		legacy.Cell.LAC = uint32(lac)
+
+		if err := fn(legacy); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// MoveLegacyCell stores cell under its global key and removes the old key.
+// An existing entry under the global key is kept if it has more
+// observations. It reports whether the cell was written.
+func (c *RedisCache) MoveLegacyCell(ctx context.Context, oldKey string, cell *model.CachedCell) (bool, error) {
+	existing, err := c.GetCell(ctx, cell.Key())
+	if err != nil {
+		return false, err
+	}
+	written := existing == nil || existing.ObsCount < cell.ObsCount
+	if written {
+		if err := c.SetCell(ctx, cell); err != nil {
+			return false, err
+		}
+	}
+	return written, c.client.Del(ctx, oldKey).Err()
 }
 
 // ============================================
@@ -132,6 +200,94 @@ func (c *RedisCache) SetBT(ctx context.Context, bt *model.CachedBT) error {
 	return c.client.Set(ctx, key, data, 0).Err()
 }
 
+// ============================================
+// WiFi Fingerprint Operations
+// ============================================
+
+// Fingerprints are stored as JSON under fp:{id}; fpap:{bssid} indexes the
+// fingerprints an AP appears in.
+
+func (c *RedisCache) GetFingerprint(ctx context.Context, id string) (*model.WifiFingerprint, error) {
+	data, err := c.client.Get(ctx, "fp:"+id).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fp model.WifiFingerprint
+	if err := json.Unmarshal([]byte(data), &fp); err != nil {
+		return nil, err
+	}
+	return &fp, nil
+}
+
+// SetFingerprint stores fp and indexes its APs. dropped are BSSIDs removed
+// from the fingerprint since it was read.
+func (c *RedisCache) SetFingerprint(ctx context.Context, fp *model.WifiFingerprint, dropped []string) error {
+	data, err := json.Marshal(fp)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.Set(ctx, "fp:"+fp.ID, data, 0)
+	for bssid := range fp.APs {
+		pipe.SAdd(ctx, "fpap:"+bssid, fp.ID)
+	}
+	for _, bssid := range dropped {
+		pipe.SRem(ctx, "fpap:"+bssid, fp.ID)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// FingerprintsWithAPs returns up to limit fingerprints containing any of
+// the given BSSIDs.
+func (c *RedisCache) FingerprintsWithAPs(ctx context.Context, bssids []string, limit int) ([]model.WifiFingerprint, error) {
+	if len(bssids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(bssids))
+	for i, b := range bssids {
+		keys[i] = "fpap:" + b
+	}
+	ids, err := c.client.SUnion(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	fpKeys := make([]string, len(ids))
+	for i, id := range ids {
+		fpKeys[i] = "fp:" + id
+	}
+	results, err := c.client.MGet(ctx, fpKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	fps := make([]model.WifiFingerprint, 0, len(results))
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		var fp model.WifiFingerprint
+		if err := json.Unmarshal([]byte(r.(string)), &fp); err != nil {
+			continue
+		}
+		fps = append(fps, fp)
+	}
+	return fps, nil
+}
+
 // ============================================
 // Device Position Operations
 // ============================================
@@ -162,36 +318,669 @@ func (c *RedisCache) SetDevicePosition(ctx context.Context, pos *model.DevicePos
 	return c.client.Set(ctx, key, data, 0).Err()
 }
 
+// setIfNewerScript stores the position only if it is not older than the
+// stored one, so late-arriving points never regress the latest position.
+var setIfNewerScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if cur then
+	local ts = cjson.decode(cur)['timestamp']
+	if ts and ts > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+redis.call('SET', KEYS[1], ARGV[1])
+return 1
+`)
+
+// SetDevicePositionIfNewer stores pos unless a newer position is already
+// cached. It reports whether pos was stored.
+func (c *RedisCache) SetDevicePositionIfNewer(ctx context.Context, pos *model.DevicePosition) (bool, error) {
+	key := fmt.Sprintf("device:%s", pos.DeviceID)
+	data, err := json.Marshal(pos)
+	if err != nil {
+		return false, err
+	}
+	stored, err := setIfNewerScript.Run(ctx, c.client, []string{key}, data, pos.Timestamp).Int()
+	if err != nil {
+		return false, err
+	}
+	return stored == 1, nil
+}
+
+// ============================================
+// Device Clock Operations
+// ============================================
+
+func (c *RedisCache) GetClockSkew(ctx context.Context, deviceID string) (*model.ClockSkew, error) {
+	key := fmt.Sprintf("clock:%s", deviceID)
+	data, err := c.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var skew model.ClockSkew
+	if err := json.Unmarshal([]byte(data), &skew); err != nil {
+		return nil, err
+	}
+	return &skew, nil
+}
+
+func (c *RedisCache) SetClockSkew(ctx context.Context, deviceID string, skew *model.ClockSkew) error {
+	key := fmt.Sprintf("clock:%s", deviceID)
+	data, err := json.Marshal(skew)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, key, data, 0).Err()
+}
+
+// ============================================
+// Alert Operations
+// ============================================
+
+// MarkAlert records an alert of the given type for a device. It reports
+// false if one was already recorded within ttl.
+func (c *RedisCache) MarkAlert(ctx context.Context, alertType, deviceID string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("alert:%s:%s", alertType, deviceID)
+	return c.client.SetNX(ctx, key, time.Now().Unix(), ttl).Result()
+}
+
+// ============================================
+// Excluded Source Operations
+// ============================================
+
+// ExcludeSource records a source learning refuses to position and, if
+// dropLearned is set, drops any position learned for it before. Each type
+// keeps the newest max sources, in order of first exclusion.
+func (c *RedisCache) ExcludeSource(ctx context.Context, ex *model.ExcludedSource, max int64, dropLearned bool) error {
+	index := fmt.Sprintf("excluded:%s", ex.PointType)
+	data, err := json.Marshal(ex)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.ZAddNX(ctx, index, redis.Z{Score: float64(ex.DetectedAt), Member: ex.PointID})
+	pipe.HSet(ctx, "excluded_sources", model.CompanionKey(ex.PointType, ex.PointID), data)
+	if dropLearned {
+		switch ex.PointType {
+		case model.PointTypeWifi:
+			pipe.Del(ctx, fmt.Sprintf("wifi:%s", ex.PointID))
+		case model.PointTypeBT:
+			pipe.Del(ctx, fmt.Sprintf("bt:%s", ex.PointID))
+		}
+	}
+	count := pipe.ZCard(ctx, index)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if max <= 0 || count.Val() <= max {
+		return nil
+	}
+
+	// Forget the oldest beyond max
+	oldest, err := c.client.ZRange(ctx, index, 0, count.Val()-max-1).Result()
+	if err != nil || len(oldest) == 0 {
+		return err
+	}
+	fields := make([]string, len(oldest))
+	members := make([]interface{}, len(oldest))
+	for i, id := range oldest {
+		fields[i] = model.CompanionKey(ex.PointType, id)
+		members[i] = id
+	}
+	pipe = c.client.TxPipeline()
+	pipe.ZRem(ctx, index, members...)
+	pipe.HDel(ctx, "excluded_sources", fields...)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// IncludeSource forgets an excluded source.
+func (c *RedisCache) IncludeSource(ctx context.Context, pointType model.PointType, pointID string) error {
+	pipe := c.client.TxPipeline()
+	pipe.ZRem(ctx, fmt.Sprintf("excluded:%s", pointType), pointID)
+	pipe.HDel(ctx, "excluded_sources", model.CompanionKey(pointType, pointID))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ListExcludedSources returns up to limit excluded sources of a type, or of
+// every type if pointType is empty, most recently excluded first.
+func (c *RedisCache) ListExcludedSources(ctx context.Context, pointType model.PointType, limit int64) ([]model.ExcludedSource, error) {
+	if pointType == "" {
+		var all []model.ExcludedSource
+		for _, pt := range []model.PointType{model.PointTypeWifi, model.PointTypeCell, model.PointTypeBT} {
+			sources, err := c.ListExcludedSources(ctx, pt, limit)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, sources...)
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].DetectedAt > all[j].DetectedAt })
+		if int64(len(all)) > limit {
+			all = all[:limit]
+		}
+		return all, nil
+	}
+
+	index := fmt.Sprintf("excluded:%s", pointType)
+	ids, err := c.client.ZRevRangeWithScores(ctx, index, 0, limit-1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	fields := make([]string, len(ids))
+	for i, z := range ids {
+		fields[i] = model.CompanionKey(pointType, z.Member.(string))
+	}
+	values, err := c.client.HMGet(ctx, "excluded_sources", fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]model.ExcludedSource, 0, len(values))
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var ex model.ExcludedSource
+		if err := json.Unmarshal([]byte(data), &ex); err != nil {
+			continue
+		}
+		ex.DetectedAt = int64(ids[i].Score)
+		sources = append(sources, ex)
+	}
+	return sources, nil
+}
+
+// ============================================
+// Source Relocation Operations
+// ============================================
+
+// PushRelocation records a source move, keeping the newest size moves.
+func (c *RedisCache) PushRelocation(ctx context.Context, event *model.RelocationEvent, size int64) error {
+	key := fmt.Sprintf("relocations:%s:%s", event.PointType, event.PointID)
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, size-1)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetRelocations returns the recorded moves of a source, newest first.
+func (c *RedisCache) GetRelocations(ctx context.Context, pointType model.PointType, pointID string) ([]model.RelocationEvent, error) {
+	key := fmt.Sprintf("relocations:%s:%s", pointType, pointID)
+	members, err := c.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]model.RelocationEvent, 0, len(members))
+	for _, m := range members {
+		var e model.RelocationEvent
+		if err := json.Unmarshal([]byte(m), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// ============================================
+// Clone Detection Operations
+// ============================================
+
+// PushSighting adds a raw fix to the device sighting window, trims it to the
+// newest size fixes and returns the window oldest first.
+func (c *RedisCache) PushSighting(ctx context.Context, deviceID string, p *model.Sighting, size int, ttl time.Duration) ([]model.Sighting, error) {
+	key := fmt.Sprintf("sightings:%s", deviceID)
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(p.Timestamp), Member: data})
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-size-1))
+	pipe.Expire(ctx, key, ttl)
+	window := pipe.ZRange(ctx, key, 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	sightings := make([]model.Sighting, 0, size)
+	for _, m := range window.Val() {
+		var s model.Sighting
+		if err := json.Unmarshal([]byte(m), &s); err != nil {
+			continue
+		}
+		sightings = append(sightings, s)
+	}
+	return sightings, nil
+}
+
+// RecordScan registers that a device reported the WiFi scan with the given
+// signature at ts. It returns the other devices that reported the same scan
+// within window of ts, or nothing if the device had already reported it.
+func (c *RedisCache) RecordScan(ctx context.Context, signature, deviceID string, ts int64, window time.Duration) ([]string, error) {
+	key := fmt.Sprintf("scan:%s", signature)
+	w := int64(window.Seconds())
+
+	pipe := c.client.TxPipeline()
+	added := pipe.ZAddNX(ctx, key, redis.Z{Score: float64(ts), Member: deviceID})
+	devices := pipe.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: fmt.Sprint(ts - w),
+		Max: fmt.Sprint(ts + w),
+	})
+	pipe.Expire(ctx, key, 2*window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	if added.Val() == 0 {
+		return nil, nil
+	}
+
+	var others []string
+	for _, d := range devices.Val() {
+		if d != deviceID {
+			others = append(others, d)
+		}
+	}
+	return others, nil
+}
+
+// IncrClonePair counts one more scan shared by two devices. The counter
+// expires ttl after the last shared scan.
+func (c *RedisCache) IncrClonePair(ctx context.Context, a, b string, ttl time.Duration) (int64, error) {
+	key := fmt.Sprintf("clonepair:%s|%s", a, b)
+	pipe := c.client.TxPipeline()
+	n := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return n.Val(), nil
+}
+
+func (c *RedisCache) GetCloneSuspect(ctx context.Context, id string) (*model.CloneSuspect, error) {
+	data, err := c.client.HGet(ctx, "clones", id).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var suspect model.CloneSuspect
+	if err := json.Unmarshal(data, &suspect); err != nil {
+		return nil, err
+	}
+	return &suspect, nil
+}
+
+func (c *RedisCache) SetCloneSuspect(ctx context.Context, suspect *model.CloneSuspect) error {
+	data, err := json.Marshal(suspect)
+	if err != nil {
+		return err
+	}
+	return c.client.HSet(ctx, "clones", suspect.ID(), data).Err()
+}
+
+func (c *RedisCache) ListCloneSuspects(ctx context.Context) ([]model.CloneSuspect, error) {
+	all, err := c.client.HGetAll(ctx, "clones").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	suspects := make([]model.CloneSuspect, 0, len(all))
+	for _, data := range all {
+		var s model.CloneSuspect
+		if err := json.Unmarshal([]byte(data), &s); err != nil {
+			continue
+		}
+		suspects = append(suspects, s)
+	}
+	return suspects, nil
+}
+
+func (c *RedisCache) DeleteCloneSuspects(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return c.client.HDel(ctx, "clones", ids...).Err()
+}
+
+// ============================================
+// Trajectory Window Operations
+// ============================================
+
+// PushTrackPoint adds an accepted point to the device trajectory window and
+// trims it to the newest size points. The window is a sorted set scored by
+// timestamp, so points always come back in time order. It expires ttl after
+// the last point so silent devices don't leave it behind.
+func (c *RedisCache) PushTrackPoint(ctx context.Context, deviceID string, p *model.TrackPoint, size int, ttl time.Duration) error {
+	key := fmt.Sprintf("track:%s", deviceID)
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(p.Timestamp), Member: data})
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-size-1))
+	pipe.Expire(ctx, key, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetTrack returns up to limit newest points of the device trajectory
+// window, newest first.
+func (c *RedisCache) GetTrack(ctx context.Context, deviceID string, limit int) ([]model.TrackPoint, error) {
+	key := fmt.Sprintf("track:%s", deviceID)
+	members, err := c.client.ZRevRange(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	return decodeTrack(members), nil
+}
+
+// GetTrackBefore returns up to limit points strictly older than ts,
+// newest first.
+func (c *RedisCache) GetTrackBefore(ctx context.Context, deviceID string, ts int64, limit int) ([]model.TrackPoint, error) {
+	key := fmt.Sprintf("track:%s", deviceID)
+	members, err := c.client.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   fmt.Sprintf("(%d", ts),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return decodeTrack(members), nil
+}
+
+// GetTrackAfter returns up to limit points strictly newer than ts,
+// oldest first.
+func (c *RedisCache) GetTrackAfter(ctx context.Context, deviceID string, ts int64, limit int) ([]model.TrackPoint, error) {
+	key := fmt.Sprintf("track:%s", deviceID)
+	members, err := c.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min:   fmt.Sprintf("(%d", ts),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return decodeTrack(members), nil
+}
+
+func decodeTrack(members []string) []model.TrackPoint {
+	points := make([]model.TrackPoint, 0, len(members))
+	for _, m := range members {
+		var p model.TrackPoint
+		if err := json.Unmarshal([]byte(m), &p); err != nil {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// ============================================
+// Geofence Operations
+// ============================================
+
+func (c *RedisCache) ListGeofences(ctx context.Context) ([]model.Geofence, error) {
+	all, err := c.client.HGetAll(ctx, "geofences").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]model.Geofence, 0, len(all))
+	for _, data := range all {
+		var zone model.Geofence
+		if err := json.Unmarshal([]byte(data), &zone); err != nil {
+			continue
+		}
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+func (c *RedisCache) SetGeofence(ctx context.Context, zone *model.Geofence) error {
+	data, err := json.Marshal(zone)
+	if err != nil {
+		return err
+	}
+	return c.client.HSet(ctx, "geofences", zone.ID, data).Err()
+}
+
+func (c *RedisCache) DeleteGeofence(ctx context.Context, id string) error {
+	return c.client.HDel(ctx, "geofences", id).Err()
+}
+
+// ============================================
+// Transit Corridor Operations
+// ============================================
+
+func (c *RedisCache) ListCorridors(ctx context.Context) ([]model.Corridor, error) {
+	all, err := c.client.HGetAll(ctx, "corridors").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	corridors := make([]model.Corridor, 0, len(all))
+	for _, data := range all {
+		var corridor model.Corridor
+		if err := json.Unmarshal([]byte(data), &corridor); err != nil {
+			continue
+		}
+		corridors = append(corridors, corridor)
+	}
+	return corridors, nil
+}
+
+func (c *RedisCache) SetCorridor(ctx context.Context, corridor *model.Corridor) error {
+	data, err := json.Marshal(corridor)
+	if err != nil {
+		return err
+	}
+	return c.client.HSet(ctx, "corridors", corridor.ID, data).Err()
+}
+
+func (c *RedisCache) DeleteCorridor(ctx context.Context, id string) error {
+	return c.client.HDel(ctx, "corridors", id).Err()
+}
+
+// ============================================
+// Device Profile Operations
+// ============================================
+
+func (c *RedisCache) GetDeviceProfile(ctx context.Context, name string) (*model.DeviceProfile, error) {
+	data, err := c.client.HGet(ctx, "profiles", name).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var profile model.DeviceProfile
+	if err := json.Unmarshal([]byte(data), &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (c *RedisCache) SetDeviceProfile(ctx context.Context, profile *model.DeviceProfile) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	return c.client.HSet(ctx, "profiles", profile.Name, data).Err()
+}
+
+func (c *RedisCache) DeleteDeviceProfile(ctx context.Context, name string) error {
+	return c.client.HDel(ctx, "profiles", name).Err()
+}
+
+func (c *RedisCache) ListDeviceProfiles(ctx context.Context) ([]model.DeviceProfile, error) {
+	all, err := c.client.HGetAll(ctx, "profiles").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]model.DeviceProfile, 0, len(all))
+	for _, data := range all {
+		var profile model.DeviceProfile
+		if err := json.Unmarshal([]byte(data), &profile); err != nil {
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// GetDeviceProfileName returns the profile explicitly assigned to a device,
+// or "" if there is none.
+func (c *RedisCache) GetDeviceProfileName(ctx context.Context, deviceID string) (string, error) {
+	name, err := c.client.HGet(ctx, "profile_devices", deviceID).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return name, err
+}
+
+// SetDeviceProfileName assigns a profile to a device. An empty name removes
+// the assignment.
+func (c *RedisCache) SetDeviceProfileName(ctx context.Context, deviceID, name string) error {
+	if name == "" {
+		return c.client.HDel(ctx, "profile_devices", deviceID).Err()
+	}
+	return c.client.HSet(ctx, "profile_devices", deviceID, name).Err()
+}
+
+func (c *RedisCache) GetDeviceProfileNames(ctx context.Context) (map[string]string, error) {
+	return c.client.HGetAll(ctx, "profile_devices").Result()
+}
+
+// GetProfilePrefixes returns device ID prefix -> profile name assignments.
+func (c *RedisCache) GetProfilePrefixes(ctx context.Context) (map[string]string, error) {
+	return c.client.HGetAll(ctx, "profile_prefixes").Result()
+}
+
+// SetProfilePrefix assigns a profile to every device ID starting with prefix.
+// An empty name removes the assignment.
+func (c *RedisCache) SetProfilePrefix(ctx context.Context, prefix, name string) error {
+	if name == "" {
+		return c.client.HDel(ctx, "profile_prefixes", prefix).Err()
+	}
+	return c.client.HSet(ctx, "profile_prefixes", prefix, name).Err()
+}
+
 // ============================================
 // Companion Detection
 // ============================================
 
-func (c *RedisCache) AddCompanion(ctx context.Context, objectID, pointID string, pointType model.PointType) error {
-	key := fmt.Sprintf("companions:%s", objectID)
-	member := fmt.Sprintf("%s:%s", pointType, pointID)
-	return c.client.SAdd(ctx, key, member).Err()
+// GetCompanionState returns the object's window count and what is known
+// about the given sources, keyed by CompanionKey. Unknown sources are absent.
+func (c *RedisCache) GetCompanionState(ctx context.Context, objectID string, keys []string) (*model.CompanionTrack, map[string]*model.CompanionSource, error) {
+	pipe := c.client.Pipeline()
+	trackCmd := pipe.Get(ctx, fmt.Sprintf("companion_track:%s", objectID))
+	sourcesCmd := pipe.HMGet(ctx, fmt.Sprintf("companion:%s", objectID), keys...)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, nil, err
+	}
+
+	var track *model.CompanionTrack
+	if data, err := trackCmd.Bytes(); err == nil {
+		track = &model.CompanionTrack{}
+		if err := json.Unmarshal(data, track); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sources := make(map[string]*model.CompanionSource, len(keys))
+	for i, v := range sourcesCmd.Val() {
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var src model.CompanionSource
+		if err := json.Unmarshal([]byte(data), &src); err != nil {
+			continue
+		}
+		sources[keys[i]] = &src
+	}
+	return track, sources, nil
+}
+
+// SetCompanionState stores the object's window count and sources. Both keys
+// expire ttl after the object was last learned from.
+func (c *RedisCache) SetCompanionState(ctx context.Context, objectID string, track *model.CompanionTrack, sources []model.CompanionSource, ttl time.Duration) error {
+	trackKey := fmt.Sprintf("companion_track:%s", objectID)
+	key := fmt.Sprintf("companion:%s", objectID)
+
+	trackData, err := json.Marshal(track)
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, 0, 2*len(sources))
+	for i := range sources {
+		data, err := json.Marshal(&sources[i])
+		if err != nil {
+			return err
+		}
+		values = append(values, model.CompanionKey(sources[i].PointType, sources[i].PointID), data)
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.Set(ctx, trackKey, trackData, ttl)
+	if len(values) > 0 {
+		pipe.HSet(ctx, key, values...)
+	}
+	pipe.Expire(ctx, key, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
 }
 
+// GetCompanions returns every source tracked for the object.
 func (c *RedisCache) GetCompanions(ctx context.Context, objectID string) ([]model.CompanionSource, error) {
-	key := fmt.Sprintf("companions:%s", objectID)
-	members, err := c.client.SMembers(ctx, key).Result()
+	key := fmt.Sprintf("companion:%s", objectID)
+	all, err := c.client.HGetAll(ctx, key).Result()
 	if err != nil {
 		return nil, err
 	}
 
-	var companions []model.CompanionSource
-	for _, m := range members {
-		var pt model.PointType
-		var pointID string
-		fmt.Sscanf(m, "%s:%s", &pt, &pointID)
-		companions = append(companions, model.CompanionSource{
-			PointID:   pointID,
-			PointType: pt,
-		})
+	companions := make([]model.CompanionSource, 0, len(all))
+	for _, data := range all {
+		var src model.CompanionSource
+		if err := json.Unmarshal([]byte(data), &src); err != nil {
+			continue
+		}
+		companions = append(companions, src)
 	}
 	return companions, nil
 }
 
+// DeleteCompanions forgets sources of the object, by CompanionKey.
+func (c *RedisCache) DeleteCompanions(ctx context.Context, objectID string, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.HDel(ctx, fmt.Sprintf("companion:%s", objectID), keys...).Err()
+}
+
 // ============================================
 // Batch Operations for Learning
 // ============================================
@@ -225,17 +1014,15 @@ func (c *RedisCache) MGetWifi(ctx context.Context, bssids []string) (map[string]
 	return m, nil
 }
 
-func (c *RedisCache) MGetCell(ctx context.Context, cells []struct {
-	CellID uint32
-	LAC    uint32
-}) (map[string]*model.CachedCell, error) {
+// MGetCell returns the cached cells found, keyed by CellKey.String().
+func (c *RedisCache) MGetCell(ctx context.Context, cells []model.CellKey) (map[string]*model.CachedCell, error) {
 	if len(cells) == 0 {
 		return nil, nil
 	}
 
 	keys := make([]string, len(cells))
-	for i, c := range cells {
-		keys[i] = fmt.Sprintf("cell:%d:%d", c.CellID, c.LAC)
+	for i, k := range cells {
+		keys[i] = cellKey(k)
 	}
 
 	results, err := c.client.MGet(ctx, keys...).Result()
@@ -252,8 +1039,7 @@ func (c *RedisCache) MGetCell(ctx context.Context, cells []struct {
 		if err := json.Unmarshal([]byte(r.(string)), &cell); err != nil {
 			continue
 		}
-		key := fmt.Sprintf("%d:%d", cells[i].CellID, cells[i].LAC)
-		m[key] = &cell
+		m[cells[i].String()] = &cell
 	}
 	return m, nil
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -43,6 +44,7 @@ type KafkaConfig struct {
 	Brokers      []string
 	RefinementTopic string
 	LearningTopic  string
+	AlertTopic     string
 	ProducerID    string
 }
 
@@ -50,6 +52,210 @@ type ValidationConfig struct {
 	MaxSpeedKmH    float64
 	MaxTimeDiff    time.Duration
 	ConfidenceThresholds ConfidenceThresholds
+	Positioning    PositioningConfig
+	Trajectory     TrajectoryConfig
+	Kalman         KalmanConfig
+	Gap            GapConfig
+	GNSS           GNSSConfig
+	Sanity         SanityConfig
+	Clock          ClockConfig
+	Profiles       ProfileConfig
+	Geofence       GeofenceConfig
+	Corridor       CorridorConfig
+	Country        CountryConfig
+	Fingerprint    FingerprintConfig
+	Spoofing       SpoofingConfig
+	Clone          CloneConfig
+	Companion      CompanionConfig
+	Learning       LearningConfig
+	Relocation     RelocationConfig
+	Exclusion      ExclusionConfig
+	Rules          []RuleConfig
+}
+
+type GapConfig struct {
+	MinGap               time.Duration // silence after which the reappearance check runs
+	LongGap              time.Duration // silence after which the speed limit is tightened
+	SustainedSpeedFactor float64       // share of max speed plausible as an average over a long gap
+	PredictionHorizon    time.Duration // how far the last velocity is extrapolated
+	SpreadFactor         float64       // share of the speed limit at which the reappearance region grows
+}
+
+type GNSSConfig struct {
+	MaxCompareInterval       time.Duration // max time to the previous fix for speed/course comparison
+	SpeedToleranceKmH        float64
+	CourseToleranceDeg       float64
+	MinSatellites            int32   // fewer satellites can't support SuspiciousAccuracyMeters
+	MaxHDOP                  float32 // higher HDOP can't support SuspiciousAccuracyMeters
+	SuspiciousAccuracyMeters float64
+	MismatchPenalty          float64 // confidence removed by a speed or course mismatch
+	SpoofingPenalty          float64 // confidence removed by implausible accuracy or a mock provider
+}
+
+type ClockConfig struct {
+	SmoothingFactor float64       // weight of a new observation in the running offset
+	MinSamples      int           // observations before timestamps are corrected
+	DriftThreshold  time.Duration // offsets beyond this flag the device
+	FutureTolerance time.Duration // slack for corrected timestamps ahead of server time
+	BufferedGap     time.Duration // observations this far behind the offset are buffered uploads
+}
+
+type ProfileConfig struct {
+	RefreshInterval time.Duration // how often profiles and prefixes are reloaded from Redis
+}
+
+type GeofenceConfig struct {
+	Files            []string      // GeoJSON files loaded at startup
+	RefreshInterval  time.Duration // how often zones are reloaded from Redis
+	IndexCellDegrees float64       // spatial index grid cell size
+}
+
+type CorridorConfig struct {
+	RefreshInterval time.Duration // how often corridors are reloaded from Redis
+}
+
+type CountryConfig struct {
+	BoundariesFile        string  // GeoJSON country boundaries; empty disables the check
+	MCCFile               string  // optional "mcc,country" CSV replacing the built-in table
+	BorderToleranceMeters float64 // cells of a country this close to its border are accepted
+	MismatchPenalty       float64 // confidence removed when no cell's country matches
+	IndexCellDegrees      float64 // spatial index grid cell size
+}
+
+type FingerprintConfig struct {
+	CellMeters        float64 // size of the grid cell a fingerprint covers
+	MaxAPs            int     // least seen APs beyond this are dropped from a fingerprint
+	Neighbors         int     // k best matching fingerprints used for the location
+	MaxCandidates     int     // fingerprints compared per scan
+	MinKnownAPs       int     // scan APs that must be in the data before co-visibility is judged
+	RSSIScaleDB       float64 // RMS RSSI difference halving the similarity
+	MaxDistanceMeters float64 // allowed distance from the matched location, plus accuracy
+	Penalty           float64 // confidence removed on a mismatch
+}
+
+type SpoofingConfig struct {
+	WindowSize       int           // history points analysed with the fix
+	MinPoints        int           // points needed for the motion, accuracy and grid patterns
+	MaxSpeedCV       float64       // speed coefficient of variation below which motion is synthetic
+	MaxHeadingStdDeg float64       // heading change deviation below which motion is synthetic
+	MinRepeats       int           // identical coordinates with distinct timestamps
+	GridMinStepDeg   float64       // coordinate changes in multiples of this or coarser snap to a grid
+	AlertThreshold   float64       // score at which an alert is sent
+	AlertCooldown    time.Duration // min time between alerts for a device
+}
+
+type CloneConfig struct {
+	SightingWindow      int           // raw fixes kept per device, accepted or not
+	SightingTTL         time.Duration // sightings of silent devices expire after this
+	MaxSpeedKmh         float64       // faster jumps between fixes are impossible for one unit
+	ClusterRadiusMeters float64       // a fix this close to an earlier place returns to it
+	MinBounces          int           // impossible returns before a device is flagged
+	ScanMinAPs          int           // smaller WiFi scans are too common to compare
+	ScanWindow          time.Duration // identical scans this close in time are shared
+	MinSharedScans      int           // shared scans before a pair is flagged
+	SuspectTTL          time.Duration // suspects not seen again are dropped after this
+	AlertCooldown       time.Duration // min time between alerts for a device or pair
+}
+
+type CompanionConfig struct {
+	Window          time.Duration // a source counts once per window
+	PlaceMeters     float64       // grid cell size used to tell places apart
+	MinObservations int           // windows a source must be seen in before it is classified
+	MinPlaces       int           // place changes a companion must follow the object through
+	MinStability    float64       // share of the object's windows a companion is seen in
+	StaleAfter      time.Duration // sources not seen for this long are forgotten
+}
+
+type LearningConfig struct {
+	// Sources learned fewer times are UNDETERMINED
+	MinObservations int64
+	// Max standard deviation of the positions a STATIONARY source was
+	// learned at, along either axis
+	StationaryWifiMeters float64
+	StationaryBLEMeters  float64
+	StationaryCellMeters float64
+
+	// Learned positions kept per source for the position estimate
+	ReservoirSize int
+	// Share of the learned positions the coverage radius encloses
+	CoverageQuantile float64
+	// Spread of the learned positions (median distance from the source) at
+	// which its confidence is halved
+	SpreadWifiMeters float64
+	SpreadBLEMeters  float64
+	SpreadCellMeters float64
+}
+
+type RelocationConfig struct {
+	// Learned positions farther than this from the cached one count
+	// towards a move; 0 disables relocation for the source type
+	DistanceWifiMeters float64
+	DistanceBLEMeters  float64
+	DistanceCellMeters float64
+	QuarantineAfter    int64         // consecutive far positions before validation ignores the source
+	MinObservations    int64         // agreeing far positions that confirm a move
+	MinDuration        time.Duration // time the far positions must span
+	HistorySize        int64         // moves kept per source
+}
+
+type ExclusionConfig struct {
+	RandomizedMAC bool     // exclude rotating BLE addresses and list mobile APs with randomized BSSIDs as such
+	SSIDPatterns  []string // case-insensitive SSID regular expressions of hotspots and head units
+	MaxRecorded   int64    // excluded sources kept per type for GetExcludedPoints
+}
+
+type SanityConfig struct {
+	// Fixes whose latitude and longitude both have fewer decimals are
+	// rejected as truncated; 0 disables the check
+	MinCoordinateDecimals int
+}
+
+// RuleConfig controls one rule of the validation chain.
+type RuleConfig struct {
+	Name         string
+	Enabled      bool
+	Weight       float64 // exponent applied to the rule's confidence multiplier
+	ShortCircuit bool    // stop the chain with INVALID when the rule fails
+}
+
+type PositioningConfig struct {
+	// Uncertainty radius of a source reported without RSSI
+	RadiusWifiMeters float64
+	RadiusBLEMeters  float64
+	RadiusCellMeters float64
+	MinSources       int
+
+	// Log-distance path loss models turning RSSI into a distance range
+	PathLossWifi24 PathLossConfig
+	PathLossWifi5  PathLossConfig // 5 and 6 GHz
+	PathLossBLE    PathLossConfig
+	PathLossCell   PathLossConfig
+	RangeSigmas    float64 // shadowing deviations covered by a distance range
+}
+
+// PathLossConfig is a log-distance model:
+// RSSI(d) = RefRSSI - 10 * Exponent * log10(d / RefDistanceMeters) + N(0, SigmaDB²).
+type PathLossConfig struct {
+	RefDistanceMeters float64
+	RefRSSI           float64 // dBm at RefDistanceMeters
+	Exponent          float64
+	SigmaDB           float64
+	MaxDistanceMeters float64 // range of the source type
+}
+
+type TrajectoryConfig struct {
+	WindowSize         int
+	MaxAccelerationMS2 float64
+	MaxTurnRateDegS    float64
+	MinHeadingSpeedKmH float64
+	ReorderTolerance   time.Duration
+	TrackTTL           time.Duration // the window expires this long after its last point
+}
+
+type KalmanConfig struct {
+	AccelNoiseMS2  float64
+	InnovationGate float64
+	ResetGap       time.Duration
 }
 
 type ConfidenceThresholds struct {
@@ -86,6 +292,7 @@ func Load() *Config {
 			Brokers:        getEnvSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
 			RefinementTopic: getEnv("KAFKA_REFINEMENT_TOPIC", "coord-validation"),
 			LearningTopic:  getEnv("KAFKA_LEARNING_TOPIC", "coord-learning"),
+			AlertTopic:     getEnv("KAFKA_ALERT_TOPIC", "coord-alerts"),
 			ProducerID:     getEnv("KAFKA_PRODUCER_ID", "validator"),
 		},
 		Validation: ValidationConfig{
@@ -96,10 +303,182 @@ func Load() *Config {
 				Medium: 0.5,
 				Low:    0.3,
 			},
+			Positioning: PositioningConfig{
+				RadiusWifiMeters: getFloatEnv("POSITIONING_RADIUS_WIFI_METERS", 50),
+				RadiusBLEMeters:  getFloatEnv("POSITIONING_RADIUS_BLE_METERS", 5),
+				RadiusCellMeters: getFloatEnv("POSITIONING_RADIUS_CELL_METERS", 3000),
+				MinSources:       getIntEnv("POSITIONING_MIN_SOURCES", 2),
+				PathLossWifi24:   loadPathLoss("WIFI24", PathLossConfig{1, -40, 3.0, 6, 150}),
+				PathLossWifi5:    loadPathLoss("WIFI5", PathLossConfig{1, -46, 3.5, 6, 100}),
+				PathLossBLE:      loadPathLoss("BLE", PathLossConfig{1, -59, 2.2, 6, 50}),
+				PathLossCell:     loadPathLoss("CELL", PathLossConfig{1000, -75, 3.5, 8, 35000}),
+				RangeSigmas:      getFloatEnv("PATHLOSS_RANGE_SIGMAS", 1.5),
+			},
+			Trajectory: TrajectoryConfig{
+				WindowSize:         getIntEnv("TRAJECTORY_WINDOW_SIZE", 10),
+				MaxAccelerationMS2: getFloatEnv("MAX_ACCELERATION_MS2", 10.0),
+				MaxTurnRateDegS:    getFloatEnv("MAX_TURN_RATE_DEG_S", 45.0),
+				MinHeadingSpeedKmH: getFloatEnv("MIN_HEADING_SPEED_KMH", 30.0),
+				ReorderTolerance:   getDurationEnv("REORDER_TOLERANCE", time.Hour),
+				TrackTTL:           getDurationEnv("TRAJECTORY_TTL", 7*24*time.Hour),
+			},
+			Kalman: KalmanConfig{
+				AccelNoiseMS2:  getFloatEnv("KALMAN_ACCEL_NOISE_MS2", 3.0),
+				InnovationGate: getFloatEnv("KALMAN_INNOVATION_GATE", 9.21), // chi-square 99%, 2 dof
+				ResetGap:       getDurationEnv("KALMAN_RESET_GAP", 10*time.Minute),
+			},
+			Gap: GapConfig{
+				MinGap:               getDurationEnv("GAP_MIN", 5*time.Minute),
+				LongGap:              getDurationEnv("GAP_LONG", time.Hour),
+				SustainedSpeedFactor: getFloatEnv("GAP_SUSTAINED_SPEED_FACTOR", 0.8),
+				PredictionHorizon:    getDurationEnv("GAP_PREDICTION_HORIZON", 30*time.Minute),
+				SpreadFactor:         getFloatEnv("GAP_SPREAD_FACTOR", 0.5),
+			},
+			GNSS: GNSSConfig{
+				MaxCompareInterval:       getDurationEnv("GNSS_MAX_COMPARE_INTERVAL", 2*time.Minute),
+				SpeedToleranceKmH:        getFloatEnv("GNSS_SPEED_TOLERANCE_KMH", 20.0),
+				CourseToleranceDeg:       getFloatEnv("GNSS_COURSE_TOLERANCE_DEG", 45.0),
+				MinSatellites:            int32(getIntEnv("GNSS_MIN_SATELLITES", 4)),
+				MaxHDOP:                  float32(getFloatEnv("GNSS_MAX_HDOP", 5.0)),
+				SuspiciousAccuracyMeters: getFloatEnv("GNSS_SUSPICIOUS_ACCURACY_METERS", 10.0),
+				MismatchPenalty:          getFloatEnv("GNSS_MISMATCH_PENALTY", 0.3),
+				SpoofingPenalty:          getFloatEnv("GNSS_SPOOFING_PENALTY", 0.6),
+			},
+			Sanity: SanityConfig{
+				MinCoordinateDecimals: getIntEnv("SANITY_MIN_DECIMALS", 4),
+			},
+			Clock: ClockConfig{
+				SmoothingFactor: getFloatEnv("CLOCK_SMOOTHING_FACTOR", 0.1),
+				MinSamples:      getIntEnv("CLOCK_MIN_SAMPLES", 5),
+				DriftThreshold:  getDurationEnv("CLOCK_DRIFT_THRESHOLD", 2*time.Minute),
+				FutureTolerance: getDurationEnv("CLOCK_FUTURE_TOLERANCE", 5*time.Second),
+				BufferedGap:     getDurationEnv("CLOCK_BUFFERED_GAP", 5*time.Minute),
+			},
+			Profiles: ProfileConfig{
+				RefreshInterval: getDurationEnv("PROFILE_REFRESH_INTERVAL", 30*time.Second),
+			},
+			Geofence: GeofenceConfig{
+				Files:            getEnvSlice("GEOFENCE_FILES", nil),
+				RefreshInterval:  getDurationEnv("GEOFENCE_REFRESH_INTERVAL", 30*time.Second),
+				IndexCellDegrees: getFloatEnv("GEOFENCE_INDEX_CELL_DEGREES", 0.5),
+			},
+			Corridor: CorridorConfig{
+				RefreshInterval: getDurationEnv("CORRIDOR_REFRESH_INTERVAL", 30*time.Second),
+			},
+			Country: CountryConfig{
+				BoundariesFile:        getEnv("COUNTRY_BOUNDARIES_FILE", ""),
+				MCCFile:               getEnv("COUNTRY_MCC_FILE", ""),
+				BorderToleranceMeters: getFloatEnv("COUNTRY_BORDER_TOLERANCE_METERS", 30000),
+				MismatchPenalty:       getFloatEnv("COUNTRY_MISMATCH_PENALTY", 0.7),
+				IndexCellDegrees:      getFloatEnv("COUNTRY_INDEX_CELL_DEGREES", 1),
+			},
+			Fingerprint: FingerprintConfig{
+				CellMeters:        getFloatEnv("FINGERPRINT_CELL_METERS", 50),
+				MaxAPs:            getIntEnv("FINGERPRINT_MAX_APS", 64),
+				Neighbors:         getIntEnv("FINGERPRINT_NEIGHBORS", 3),
+				MaxCandidates:     getIntEnv("FINGERPRINT_MAX_CANDIDATES", 200),
+				MinKnownAPs:       getIntEnv("FINGERPRINT_MIN_KNOWN_APS", 2),
+				RSSIScaleDB:       getFloatEnv("FINGERPRINT_RSSI_SCALE_DB", 10),
+				MaxDistanceMeters: getFloatEnv("FINGERPRINT_MAX_DISTANCE_METERS", 300),
+				Penalty:           getFloatEnv("FINGERPRINT_PENALTY", 0.4),
+			},
+			Spoofing: SpoofingConfig{
+				WindowSize:       getIntEnv("SPOOFING_WINDOW_SIZE", 20),
+				MinPoints:        getIntEnv("SPOOFING_MIN_POINTS", 6),
+				MaxSpeedCV:       getFloatEnv("SPOOFING_MAX_SPEED_CV", 0.01),
+				MaxHeadingStdDeg: getFloatEnv("SPOOFING_MAX_HEADING_STD_DEG", 0.5),
+				MinRepeats:       getIntEnv("SPOOFING_MIN_REPEATS", 3),
+				GridMinStepDeg:   getFloatEnv("SPOOFING_GRID_MIN_STEP_DEG", 0.0001),
+				AlertThreshold:   getFloatEnv("SPOOFING_ALERT_THRESHOLD", 0.6),
+				AlertCooldown:    getDurationEnv("SPOOFING_ALERT_COOLDOWN", 10*time.Minute),
+			},
+			Clone: CloneConfig{
+				SightingWindow:      getIntEnv("CLONE_SIGHTING_WINDOW", 50),
+				SightingTTL:         getDurationEnv("CLONE_SIGHTING_TTL", 24*time.Hour),
+				MaxSpeedKmh:         getFloatEnv("CLONE_MAX_SPEED_KMH", 500),
+				ClusterRadiusMeters: getFloatEnv("CLONE_CLUSTER_RADIUS_METERS", 5000),
+				MinBounces:          getIntEnv("CLONE_MIN_BOUNCES", 3),
+				ScanMinAPs:          getIntEnv("CLONE_SCAN_MIN_APS", 3),
+				ScanWindow:          getDurationEnv("CLONE_SCAN_WINDOW", 10*time.Minute),
+				MinSharedScans:      getIntEnv("CLONE_MIN_SHARED_SCANS", 3),
+				SuspectTTL:          getDurationEnv("CLONE_SUSPECT_TTL", 7*24*time.Hour),
+				AlertCooldown:       getDurationEnv("CLONE_ALERT_COOLDOWN", time.Hour),
+			},
+			Companion: CompanionConfig{
+				Window:          getDurationEnv("COMPANION_WINDOW", 5*time.Minute),
+				PlaceMeters:     getFloatEnv("COMPANION_PLACE_METERS", 500),
+				MinObservations: getIntEnv("COMPANION_MIN_OBSERVATIONS", 5),
+				MinPlaces:       getIntEnv("COMPANION_MIN_PLACES", 3),
+				MinStability:    getFloatEnv("COMPANION_MIN_STABILITY", 0.8),
+				StaleAfter:      getDurationEnv("COMPANION_STALE_AFTER", 30*24*time.Hour),
+			},
+			Learning: LearningConfig{
+				MinObservations:   int64(getIntEnv("LEARNING_MIN_OBSERVATIONS", 3)),
+				StationaryWifiMeters: getFloatEnv("LEARNING_STATIONARY_WIFI_METERS", 150),
+				StationaryBLEMeters:  getFloatEnv("LEARNING_STATIONARY_BLE_METERS", 50),
+				StationaryCellMeters: getFloatEnv("LEARNING_STATIONARY_CELL_METERS", 8000),
+				ReservoirSize:        getIntEnv("LEARNING_RESERVOIR_SIZE", 32),
+				CoverageQuantile:     getFloatEnv("LEARNING_COVERAGE_QUANTILE", 0.9),
+				SpreadWifiMeters:     getFloatEnv("LEARNING_SPREAD_WIFI_METERS", 30),
+				SpreadBLEMeters:      getFloatEnv("LEARNING_SPREAD_BLE_METERS", 15),
+				SpreadCellMeters:     getFloatEnv("LEARNING_SPREAD_CELL_METERS", 1000),
+			},
+			Relocation: RelocationConfig{
+				DistanceWifiMeters: getFloatEnv("RELOCATION_DISTANCE_WIFI_METERS", 500),
+				DistanceBLEMeters:  getFloatEnv("RELOCATION_DISTANCE_BLE_METERS", 200),
+				DistanceCellMeters: getFloatEnv("RELOCATION_DISTANCE_CELL_METERS", 15000),
+				QuarantineAfter:    int64(getIntEnv("RELOCATION_QUARANTINE_AFTER", 2)),
+				MinObservations:    int64(getIntEnv("RELOCATION_MIN_OBSERVATIONS", 5)),
+				MinDuration:        getDurationEnv("RELOCATION_MIN_DURATION", time.Hour),
+				HistorySize:        int64(getIntEnv("RELOCATION_HISTORY_SIZE", 20)),
+			},
+			Exclusion: ExclusionConfig{
+				RandomizedMAC: getBoolEnv("EXCLUSION_RANDOMIZED_MAC", true),
+				SSIDPatterns: getEnvSlice("EXCLUSION_SSID_PATTERNS", []string{
+					"^iPhone", "^AndroidAP", "^AndroidShare_", "^DIRECT-", "^MiFi",
+					"^CarPlay", "^Audi_MMI_", "^myVW", "^FordPass", "^OnStar",
+				}),
+				MaxRecorded: int64(getIntEnv("EXCLUSION_MAX_RECORDED", 10000)),
+			},
+			Rules: loadRules(getEnv("VALIDATION_RULES", "time,geofence,country,speed,gnss,spoofing,triangulation,track")),
 		},
 	}
 }
 
+// loadRules builds the rule chain from a comma-separated list of rule names.
+// Each rule is tuned with RULE_<NAME>_ENABLED, RULE_<NAME>_WEIGHT and
+// RULE_<NAME>_SHORT_CIRCUIT; only the time rule short-circuits by default.
+func loadRules(order string) []RuleConfig {
+	var rules []RuleConfig
+	for _, name := range strings.Split(order, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "RULE_" + strings.ToUpper(name) + "_"
+		rules = append(rules, RuleConfig{
+			Name:         name,
+			Enabled:      getBoolEnv(prefix+"ENABLED", true),
+			Weight:       getFloatEnv(prefix+"WEIGHT", 1.0),
+			ShortCircuit: getBoolEnv(prefix+"SHORT_CIRCUIT", name == "time"),
+		})
+	}
+	return rules
+}
+
+// loadPathLoss reads a path loss model from PATHLOSS_<NAME>_REF_DISTANCE,
+// _REF_RSSI, _EXPONENT, _SIGMA_DB and _MAX_DISTANCE.
+func loadPathLoss(name string, def PathLossConfig) PathLossConfig {
+	prefix := "PATHLOSS_" + name + "_"
+	return PathLossConfig{
+		RefDistanceMeters: getFloatEnv(prefix+"REF_DISTANCE", def.RefDistanceMeters),
+		RefRSSI:           getFloatEnv(prefix+"REF_RSSI", def.RefRSSI),
+		Exponent:          getFloatEnv(prefix+"EXPONENT", def.Exponent),
+		SigmaDB:           getFloatEnv(prefix+"SIGMA_DB", def.SigmaDB),
+		MaxDistanceMeters: getFloatEnv(prefix+"MAX_DISTANCE", def.MaxDistanceMeters),
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -125,6 +504,15 @@ func getFloatEnv(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+func getBoolEnv(key string, defaultValue bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if v := os.Getenv(key); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
@@ -136,7 +524,13 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 
 func getEnvSlice(key string, defaultValue []string) []string {
 	if v := os.Getenv(key); v != "" {
-		return []string{v}
+		var out []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+		return out
 	}
 	return defaultValue
 }
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Device Clock Skew
+// ============================================
+//
+// Every request yields an observation device_ts - server_ts = skew - delay.
+// During warm-up the offset is the median of the observations, so a few
+// far-off timestamps (a GPS week rollover, a burst of bad fixes) can't set
+// it; afterwards it follows a clipped exponential average so single
+// outliers can't drag it. Observations far behind the estimate are buffered
+// uploads and say nothing about the clock. Offsets beyond MaxTimeDiff are
+// never applied: such a clock is reported, and its timestamps are checked
+// as reported.
+
+// Smallest clipping limit for the running offset (timestamps are whole seconds)
+const minClockStep = 1.0
+
+// observeClock feeds the request timestamp into the device's clock estimate
+// and returns the updated estimate.
+func (v *ValidationCore) observeClock(ctx context.Context, req *model.CoordinateRequest) (*model.ClockSkew, error) {
+	prev, err := v.cache.GetClockSkew(ctx, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	skew := v.updateSkew(prev, float64(req.Timestamp-now), now)
+	if err := v.cache.SetClockSkew(ctx, req.DeviceID, skew); err != nil {
+		return nil, err
+	}
+	return skew, nil
+}
+
+func (v *ValidationCore) updateSkew(prev *model.ClockSkew, observed float64, now int64) *model.ClockSkew {
+	cfg := v.cfg.Clock
+	if prev == nil || prev.Samples == 0 {
+		prev = &model.ClockSkew{}
+	}
+
+	s := *prev
+	if s.Samples < int64(cfg.MinSamples) {
+		// Every observation counts: the median outvotes buffered uploads
+		s.WarmUp = append(append([]float64(nil), s.WarmUp...), observed)
+		s.OffsetSeconds = median(append([]float64(nil), s.WarmUp...))
+		deviations := make([]float64, len(s.WarmUp))
+		for i, o := range s.WarmUp {
+			deviations[i] = math.Abs(o - s.OffsetSeconds)
+		}
+		s.DeviationSeconds = median(deviations)
+		if s.Samples+1 >= int64(cfg.MinSamples) {
+			s.WarmUp = nil
+		}
+	} else {
+		residual := observed - s.OffsetSeconds
+		if residual < -cfg.BufferedGap.Seconds() {
+			return &s
+		}
+		limit := math.Max(3*s.DeviationSeconds, minClockStep)
+		clipped := math.Max(-limit, math.Min(limit, residual))
+		s.OffsetSeconds += cfg.SmoothingFactor * clipped
+		s.DeviationSeconds += cfg.SmoothingFactor * (math.Abs(clipped) - s.DeviationSeconds)
+	}
+	s.Samples++
+	s.UpdatedAt = now
+	s.Drifting = s.Samples >= int64(cfg.MinSamples) &&
+		math.Abs(s.OffsetSeconds) > cfg.DriftThreshold.Seconds()
+	return &s
+}
+
+// correctTimestamp returns req shifted to server time by the learned offset.
+// Requests are returned unchanged until the estimate has warmed up, and when
+// the offset is too large to be trusted.
+func (v *ValidationCore) correctTimestamp(req *model.CoordinateRequest, skew *model.ClockSkew) *model.CoordinateRequest {
+	if skew == nil || skew.Samples < int64(v.cfg.Clock.MinSamples) || v.offsetTooLarge(skew) {
+		return req
+	}
+	corrected := *req
+	corrected.Timestamp -= int64(math.Round(skew.OffsetSeconds))
+	return &corrected
+}
+
+// offsetTooLarge reports whether the learned offset exceeds the largest
+// timestamp deviation validation accepts.
+func (v *ValidationCore) offsetTooLarge(skew *model.ClockSkew) bool {
+	return math.Abs(skew.OffsetSeconds) > v.cfg.MaxTimeDiff.Seconds()
+}
+
+// clockCheck reports the learned offset; drifting clocks are flagged but
+// don't affect confidence since their timestamps are corrected. An offset
+// too large to correct fails the check, and the time check then sees the
+// timestamps as reported.
+func (v *ValidationCore) clockCheck(skew *model.ClockSkew) (model.CheckResult, bool) {
+	if skew == nil || skew.Samples < int64(v.cfg.Clock.MinSamples) {
+		return model.CheckResult{}, false
+	}
+	c := model.CheckResult{
+		Check:     model.CheckClock,
+		Code:      model.CheckCodeOK,
+		Passed:    true,
+		Value:     skew.OffsetSeconds,
+		Threshold: v.cfg.Clock.DriftThreshold.Seconds(),
+	}
+	if v.offsetTooLarge(skew) {
+		c.Code = model.CheckCodeClockOffsetTooLarge
+		c.Passed = false
+		c.Threshold = v.cfg.MaxTimeDiff.Seconds()
+		c.Message = fmt.Sprintf("Device clock offset of %.0f s is too large to correct", skew.OffsetSeconds)
+	} else if skew.Drifting {
+		c.Code = model.CheckCodeClockDrift
+		c.Passed = false
+		c.Message = fmt.Sprintf("Device clock is %.0f s off server time", skew.OffsetSeconds)
+	}
+	return c, true
+}
+
+// CorrectedTimestamp feeds the request into the device's clock estimate and
+// returns its timestamp in server time, as Validate checks it.
+func (v *ValidationCore) CorrectedTimestamp(ctx context.Context, req *model.CoordinateRequest) int64 {
+	skew, _ := v.observeClock(ctx, req)
+	return v.correctTimestamp(req, skew).Timestamp
+}
+
+// ClockOffset returns the learned clock offset of a device, nil if unknown.
+func (v *ValidationCore) ClockOffset(ctx context.Context, deviceID string) (*model.ClockSkew, error) {
+	return v.cache.GetClockSkew(ctx, deviceID)
+}
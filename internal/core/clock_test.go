@@ -0,0 +1,194 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+func testClockCore() *ValidationCore {
+	return &ValidationCore{cfg: &config.ValidationConfig{
+		MaxTimeDiff: 12 * time.Hour,
+		Clock: config.ClockConfig{
+			SmoothingFactor: 0.1,
+			MinSamples:      5,
+			DriftThreshold:  2 * time.Minute,
+			FutureTolerance: 5 * time.Second,
+			BufferedGap:     5 * time.Minute,
+		},
+	}}
+}
+
+func TestUpdateSkew(t *testing.T) {
+	v := testClockCore()
+	warmed := &model.ClockSkew{OffsetSeconds: 30, DeviationSeconds: 2, Samples: 5}
+
+	tests := []struct {
+		name      string
+		prev      *model.ClockSkew
+		observed  []float64
+		offset    float64
+		deviation float64
+		samples   int64
+		warmUp    int
+		drifting  bool
+	}{
+		{
+			name:     "first observation",
+			observed: []float64{30},
+			offset:   30,
+			samples:  1,
+			warmUp:   1,
+		},
+		{
+			name:      "warm-up takes the median, a buffered upload can't move it",
+			observed:  []float64{30, 31, -3600, 29, 30},
+			offset:    30,
+			deviation: 1,
+			samples:   5,
+		},
+		{
+			name:      "warm-up keeps its observations until MinSamples",
+			observed:  []float64{30, 31, 29},
+			offset:    30,
+			deviation: 1,
+			samples:   3,
+			warmUp:    3,
+		},
+		{
+			name:      "steady clock after warm-up",
+			prev:      warmed,
+			observed:  []float64{30},
+			offset:    30,
+			deviation: 1.8,
+			samples:   6,
+		},
+		{
+			name:      "outlier is clipped to three deviations",
+			prev:      warmed,
+			observed:  []float64{1030},
+			offset:    30.6,
+			deviation: 2.4,
+			samples:   6,
+		},
+		{
+			name:      "buffered upload after warm-up is ignored",
+			prev:      warmed,
+			observed:  []float64{-600},
+			offset:    30,
+			deviation: 2,
+			samples:   5,
+		},
+		{
+			name:      "offset beyond the drift threshold is flagged",
+			observed:  []float64{300, 300, 301, 299, 300},
+			offset:    300,
+			deviation: 0,
+			samples:   5,
+			drifting:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skew := tt.prev
+			for _, o := range tt.observed {
+				skew = v.updateSkew(skew, o, 1700000000)
+			}
+			if math.Abs(skew.OffsetSeconds-tt.offset) > 1e-9 {
+				t.Errorf("offset = %v, want %v", skew.OffsetSeconds, tt.offset)
+			}
+			if math.Abs(skew.DeviationSeconds-tt.deviation) > 1e-9 {
+				t.Errorf("deviation = %v, want %v", skew.DeviationSeconds, tt.deviation)
+			}
+			if skew.Samples != tt.samples {
+				t.Errorf("samples = %d, want %d", skew.Samples, tt.samples)
+			}
+			if len(skew.WarmUp) != tt.warmUp {
+				t.Errorf("warm-up holds %d observations, want %d", len(skew.WarmUp), tt.warmUp)
+			}
+			if skew.Drifting != tt.drifting {
+				t.Errorf("drifting = %v, want %v", skew.Drifting, tt.drifting)
+			}
+		})
+	}
+
+	if warmed.Samples != 5 || warmed.OffsetSeconds != 30 {
+		t.Errorf("previous state was modified: %+v", warmed)
+	}
+}
+
+func TestCorrectTimestamp(t *testing.T) {
+	v := testClockCore()
+	req := &model.CoordinateRequest{Timestamp: 1700000000}
+
+	tests := []struct {
+		name  string
+		skew  *model.ClockSkew
+		want  int64
+		check model.CheckCode // "" for no clock check
+	}{
+		{"no clock state", nil, 1700000000, ""},
+		{"still warming up", &model.ClockSkew{OffsetSeconds: 30, Samples: 4}, 1700000000, ""},
+		{"fast clock", &model.ClockSkew{OffsetSeconds: 29.6, Samples: 5}, 1699999970, model.CheckCodeOK},
+		{"slow clock", &model.ClockSkew{OffsetSeconds: -90, Samples: 5}, 1700000090, model.CheckCodeOK},
+		{"drifting clock is still corrected", &model.ClockSkew{OffsetSeconds: 600, Samples: 5, Drifting: true}, 1699999400, model.CheckCodeClockDrift},
+		{"offset too large to correct", &model.ClockSkew{OffsetSeconds: 13 * 3600, Samples: 5, Drifting: true}, 1700000000, model.CheckCodeClockOffsetTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.correctTimestamp(req, tt.skew).Timestamp; got != tt.want {
+				t.Errorf("timestamp = %d, want %d", got, tt.want)
+			}
+			c, ok := v.clockCheck(tt.skew)
+			if ok != (tt.check != "") || c.Code != tt.check {
+				t.Errorf("clock check = %q (%v), want %q", c.Code, ok, tt.check)
+			}
+		})
+	}
+	if req.Timestamp != 1700000000 {
+		t.Errorf("request was modified: %d", req.Timestamp)
+	}
+}
+
+func TestValidateFastClock(t *testing.T) {
+	v, _ := newTestCore(t)
+	ctx := context.Background()
+	minSamples := v.cfg.Clock.MinSamples
+
+	// A tracker 30 s ahead is rejected until its clock is learned
+	for i := 1; i <= minSamples+2; i++ {
+		req := fixAt("fast-clock", 0, 0, time.Now().Unix()+30)
+		resp, err := v.Validate(ctx, &req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var timeCheck *model.CheckResult
+		for j := range resp.Checks {
+			if resp.Checks[j].Check == model.CheckTime {
+				timeCheck = &resp.Checks[j]
+			}
+		}
+		if timeCheck == nil {
+			t.Fatalf("request %d: no time check", i)
+		}
+		want := model.CheckCodeOK
+		if i < minSamples {
+			want = model.CheckCodeFutureTimestamp
+		}
+		if timeCheck.Code != want {
+			t.Errorf("request %d: time check = %q, want %q", i, timeCheck.Code, want)
+		}
+	}
+
+	corrected := v.CorrectedTimestamp(ctx, &model.CoordinateRequest{DeviceID: "fast-clock", Timestamp: 1700000030})
+	if d := corrected - 1700000000; d < -2 || d > 2 {
+		t.Errorf("corrected timestamp is %d s off, want about 0", d)
+	}
+}
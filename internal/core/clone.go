@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Cloned Trackers
+// ============================================
+//
+// A firmware image cloned onto several units shows up in two ways: one
+// device ID reporting alternately from places no vehicle could travel
+// between, and several device IDs reporting byte-identical WiFi scans.
+// Every fix is observed, whatever its verdict, since the speed rule
+// rejects exactly the fixes that give a clone away.
+
+// observeClones records the fix and flags the device, or device pairs, when
+// the evidence of cloning is strong enough.
+func (v *ValidationCore) observeClones(ctx context.Context, req *model.CoordinateRequest) {
+	cfg := &v.cfg.Clone
+	if cfg.SightingWindow <= 0 {
+		return
+	}
+
+	sightings, err := v.cache.PushSighting(ctx, req.DeviceID, &model.Sighting{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Timestamp: req.Timestamp,
+	}, cfg.SightingWindow, cfg.SightingTTL)
+	if err != nil {
+		log.Printf("Warning: failed to record sighting of %s: %v", req.DeviceID, err)
+	} else if bounces, distance := countBounces(sightings, cfg.MaxSpeedKmh, cfg.ClusterRadiusMeters); bounces >= cfg.MinBounces {
+		v.flagClone(ctx, req, &model.CloneSuspect{
+			Reason:    model.CheckCodeInterleavedPositions,
+			DeviceIDs: []string{req.DeviceID},
+			Count:     bounces,
+			Distance:  distance,
+		})
+	}
+
+	signature, ok := scanSignature(req.Wifi, cfg.ScanMinAPs)
+	if !ok {
+		return
+	}
+	others, err := v.cache.RecordScan(ctx, signature, req.DeviceID, req.Timestamp, cfg.ScanWindow)
+	if err != nil {
+		log.Printf("Warning: failed to record scan of %s: %v", req.DeviceID, err)
+		return
+	}
+	for _, other := range others {
+		pair := []string{req.DeviceID, other}
+		sort.Strings(pair)
+		shared, err := v.cache.IncrClonePair(ctx, pair[0], pair[1], cfg.SuspectTTL)
+		if err != nil {
+			log.Printf("Warning: failed to count scans shared by %s and %s: %v", pair[0], pair[1], err)
+			continue
+		}
+		if int(shared) >= cfg.MinSharedScans {
+			v.flagClone(ctx, req, &model.CloneSuspect{
+				Reason:    model.CheckCodeSharedScans,
+				DeviceIDs: pair,
+				Count:     int(shared),
+			})
+		}
+	}
+}
+
+// countBounces counts fixes that return to an earlier place after a jump
+// too fast for one unit. Sightings are oldest first. It also returns the
+// longest such jump in meters.
+func countBounces(sightings []model.Sighting, maxSpeedKmh, radius float64) (int, float64) {
+	maxSpeed := maxSpeedKmh / 3.6
+	bounces := 0
+	longest := 0.0
+	for i := 1; i < len(sightings); i++ {
+		prev, cur := sightings[i-1], sightings[i]
+		jump := HaversineDistance(prev.Latitude, prev.Longitude, cur.Latitude, cur.Longitude) * 1000
+		if jump <= radius {
+			continue
+		}
+		dt := float64(cur.Timestamp - prev.Timestamp)
+		if dt > 0 && jump/dt <= maxSpeed {
+			continue
+		}
+
+		// The last place before the previous fix's
+		for k := i - 2; k >= 0; k-- {
+			p := sightings[k]
+			if HaversineDistance(p.Latitude, p.Longitude, prev.Latitude, prev.Longitude)*1000 <= radius {
+				continue
+			}
+			if HaversineDistance(p.Latitude, p.Longitude, cur.Latitude, cur.Longitude)*1000 <= radius {
+				bounces++
+				longest = math.Max(longest, jump)
+			}
+			break
+		}
+	}
+	return bounces, longest
+}
+
+// scanSignature hashes the BSSIDs and RSSIs of a scan. Devices side by side
+// see the same APs at different strengths, so identical signatures from two
+// devices mean the same scan was replayed.
+func scanSignature(wifi []model.WifiAP, minAPs int) (string, bool) {
+	entries := make([]string, 0, len(wifi))
+	seen := make(map[string]bool, len(wifi))
+	for _, w := range wifi {
+		bssid := strings.ToLower(w.BSSID)
+		if seen[bssid] {
+			continue
+		}
+		seen[bssid] = true
+		entries = append(entries, fmt.Sprintf("%s=%d", bssid, sourceRSSI(w.RSSI, w.EID)))
+	}
+	if len(entries) == 0 || len(entries) < minAPs {
+		return "", false
+	}
+	sort.Strings(entries)
+	sum := sha1.Sum([]byte(strings.Join(entries, ";")))
+	return hex.EncodeToString(sum[:]), true
+}
+
+// flagClone stores the suspect, keeping when it was first seen, and alerts
+// once per cooldown.
+func (v *ValidationCore) flagClone(ctx context.Context, req *model.CoordinateRequest, suspect *model.CloneSuspect) {
+	cfg := &v.cfg.Clone
+	id := suspect.ID()
+
+	now := time.Now().Unix()
+	suspect.Latitude = req.Latitude
+	suspect.Longitude = req.Longitude
+	suspect.FirstSeen = now
+	suspect.LastSeen = now
+	if existing, err := v.cache.GetCloneSuspect(ctx, id); err == nil && existing != nil {
+		suspect.FirstSeen = existing.FirstSeen
+	}
+	if err := v.cache.SetCloneSuspect(ctx, suspect); err != nil {
+		log.Printf("Warning: failed to save clone suspect %s: %v", id, err)
+	}
+
+	if v.alerts == nil {
+		return
+	}
+	subject := strings.Join(suspect.DeviceIDs, "|")
+	first, err := v.cache.MarkAlert(ctx, string(model.AlertTypeClonedDevice), subject, cfg.AlertCooldown)
+	if err != nil {
+		log.Printf("Warning: failed to record clone alert for %s: %v", subject, err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	for _, deviceID := range suspect.DeviceIDs {
+		var related []string
+		for _, d := range suspect.DeviceIDs {
+			if d != deviceID {
+				related = append(related, d)
+			}
+		}
+		err := v.alerts.SendAlert(ctx, &model.AlertEvent{
+			Type:           model.AlertTypeClonedDevice,
+			DeviceID:       deviceID,
+			RelatedDevices: related,
+			Score:          1,
+			Reasons:        []model.CheckCode{suspect.Reason},
+			Latitude:       req.Latitude,
+			Longitude:      req.Longitude,
+			Timestamp:      req.Timestamp,
+		})
+		if err != nil {
+			log.Printf("Warning: failed to send clone alert for %s: %v", deviceID, err)
+		}
+	}
+}
+
+// CloneSuspects returns the devices and device pairs flagged as clones, most
+// recent first, optionally only those involving deviceID or seen since the
+// given Unix time. Suspects not seen within the retention are dropped.
+func (v *ValidationCore) CloneSuspects(ctx context.Context, deviceID string, since int64) ([]model.CloneSuspect, error) {
+	all, err := v.cache.ListCloneSuspects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := time.Now().Add(-v.cfg.Clone.SuspectTTL).Unix()
+	var stale []string
+	suspects := make([]model.CloneSuspect, 0, len(all))
+	for _, s := range all {
+		if s.LastSeen < expired {
+			stale = append(stale, s.ID())
+			continue
+		}
+		if s.LastSeen < since || (deviceID != "" && !containsString(s.DeviceIDs, deviceID)) {
+			continue
+		}
+		suspects = append(suspects, s)
+	}
+	if err := v.cache.DeleteCloneSuspects(ctx, stale...); err != nil {
+		log.Printf("Warning: failed to drop expired clone suspects: %v", err)
+	}
+
+	sort.Slice(suspects, func(i, j int) bool { return suspects[i].LastSeen > suspects[j].LastSeen })
+	return suspects, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// recordingAlerts keeps the alerts it receives.
+type recordingAlerts struct {
+	mu     sync.Mutex
+	events []model.AlertEvent
+}
+
+func (a *recordingAlerts) SendAlert(ctx context.Context, event *model.AlertEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, *event)
+	return nil
+}
+
+func TestCountBounces(t *testing.T) {
+	// Sightings at the test origin (0) or 100 km east (1), s seconds apart
+	places := func(s int64, at ...int) []model.Sighting {
+		sightings := make([]model.Sighting, len(at))
+		for i, p := range at {
+			lat, lon := offsetLatLon(testLat, testLon, float64(p)*100000, 0)
+			sightings[i] = model.Sighting{Latitude: lat, Longitude: lon, Timestamp: int64(i) * s}
+		}
+		return sightings
+	}
+
+	tests := []struct {
+		name      string
+		sightings []model.Sighting
+		bounces   int
+		longest   float64
+	}{
+		{"two units alternating", places(60, 0, 1, 0, 1, 0), 3, 100000},
+		{"one jump, then staying", places(60, 0, 1, 1, 1), 0, 0},
+		{"round trip at a plausible speed", places(3600, 0, 1, 0, 1), 0, 0},
+		{"staying in place", places(60, 0, 0, 0, 0), 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bounces, longest := countBounces(tt.sightings, 500, 5000)
+			if bounces != tt.bounces || math.Abs(longest-tt.longest) > 10 {
+				t.Errorf("countBounces = %d, %.0f m, want %d, %.0f m", bounces, longest, tt.bounces, tt.longest)
+			}
+		})
+	}
+}
+
+func TestScanSignature(t *testing.T) {
+	scan := []model.WifiAP{
+		{BSSID: "aa:bb:cc:00:00:01", RSSI: -60},
+		{BSSID: "aa:bb:cc:00:00:02", RSSI: -70},
+		{BSSID: "aa:bb:cc:00:00:03", RSSI: -80},
+	}
+	want, ok := scanSignature(scan, 3)
+	if !ok {
+		t.Fatal("no signature for a full scan")
+	}
+
+	tests := []struct {
+		name  string
+		scan  []model.WifiAP
+		same  bool
+		valid bool
+	}{
+		{
+			name:  "reordered, upper case",
+			scan:  []model.WifiAP{scan[2], {BSSID: "AA:BB:CC:00:00:01", RSSI: -60}, scan[1]},
+			same:  true,
+			valid: true,
+		},
+		{
+			name:  "duplicate AP",
+			scan:  append([]model.WifiAP{scan[1]}, scan...),
+			same:  true,
+			valid: true,
+		},
+		{
+			name:  "different strength",
+			scan:  []model.WifiAP{scan[0], scan[1], {BSSID: scan[2].BSSID, RSSI: -81}},
+			valid: true,
+		},
+		{
+			name: "too few APs",
+			scan: scan[:2],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := scanSignature(tt.scan, 3)
+			if ok != tt.valid {
+				t.Fatalf("ok = %v, want %v", ok, tt.valid)
+			}
+			if ok && (got == want) != tt.same {
+				t.Errorf("same signature = %v, want %v", got == want, tt.same)
+			}
+		})
+	}
+}
+
+func TestObserveClones(t *testing.T) {
+	v, _ := newTestCore(t)
+	alerts := &recordingAlerts{}
+	v.SetAlertPublisher(alerts)
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	// One ID reporting from two cities a minute apart
+	for i := 0; i < 6; i++ {
+		req := fixAt("clone-1", float64(i%2)*100000, 0, now-600+int64(i)*60)
+		v.observeClones(ctx, &req)
+	}
+
+	// Two IDs replaying the same scans
+	for i := 0; i < v.cfg.Clone.MinSharedScans; i++ {
+		scan := []model.WifiAP{
+			{BSSID: "aa:bb:cc:00:00:01", RSSI: int32(-60 - i)},
+			{BSSID: "aa:bb:cc:00:00:02", RSSI: -70},
+			{BSSID: "aa:bb:cc:00:00:03", RSSI: -80},
+		}
+		for _, id := range []string{"replay-b", "replay-a"} {
+			req := fixAt(id, 0, 0, now-300+int64(i)*60)
+			req.Wifi = scan
+			v.observeClones(ctx, &req)
+		}
+	}
+
+	suspects, err := v.CloneSuspects(ctx, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]model.CloneSuspect)
+	for _, s := range suspects {
+		got[s.ID()] = s
+	}
+	if len(got) != 2 {
+		t.Errorf("suspects = %+v, want 2", suspects)
+	}
+	if s, ok := got["INTERLEAVED_POSITIONS:clone-1"]; !ok || s.Count != 4 {
+		t.Errorf("interleaved suspect = %+v, want 4 impossible returns", s)
+	}
+	if s, ok := got["SHARED_SCANS:replay-a|replay-b"]; !ok || s.Count != v.cfg.Clone.MinSharedScans {
+		t.Errorf("shared scans suspect = %+v, want %d scans", s, v.cfg.Clone.MinSharedScans)
+	}
+
+	// One alert per device, not one per flagged fix
+	sent := make(map[string]int)
+	for _, e := range alerts.events {
+		sent[fmt.Sprintf("%s:%s", e.Reasons[0], e.DeviceID)]++
+	}
+	for _, key := range []string{"INTERLEAVED_POSITIONS:clone-1", "SHARED_SCANS:replay-a", "SHARED_SCANS:replay-b"} {
+		if sent[key] != 1 {
+			t.Errorf("%s alerted %d times, want once", key, sent[key])
+		}
+	}
+
+	if filtered, _ := v.CloneSuspects(ctx, "replay-a", 0); len(filtered) != 1 {
+		t.Errorf("suspects involving replay-a = %d, want 1", len(filtered))
+	}
+}
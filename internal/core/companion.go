@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Companion Detection (Co-occurrence Analysis)
+// ============================================
+//
+// A source that travels with the object (in-vehicle BLE, a hotspot on
+// board) is seen in most of the object's time windows and keeps being seen
+// as the object changes place. A stationary source is only seen where it
+// stands. Companions say nothing about where the object is, so they are
+// never learned as positioned sources.
+
+// Forgotten sources are pruned every this many object windows
+const companionPruneWindows = 50
+
+type companionRef struct {
+	pointType model.PointType
+	pointID   string
+}
+
+// observeCompanions updates the co-occurrence counts of the sources in the
+// request and returns the keys of those classified as companions.
+func (l *LearningCore) observeCompanions(ctx context.Context, req *model.LearnRequest) map[string]bool {
+	cfg := &l.cfg.Companion
+
+	var refs []companionRef
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(pt model.PointType, id string) {
+		key := model.CompanionKey(pt, id)
+		if id == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		refs = append(refs, companionRef{pt, id})
+		keys = append(keys, key)
+	}
+	for _, w := range req.Wifi {
+		add(model.PointTypeWifi, w.BSSID)
+	}
+	for _, c := range req.CellTowers {
+		add(model.PointTypeCell, c.Key().String())
+	}
+	for _, b := range req.Bluetooth {
+		add(model.PointTypeBT, b.MAC)
+	}
+	if len(refs) == 0 || cfg.Window <= 0 {
+		return nil
+	}
+
+	track, known, err := l.cache.GetCompanionState(ctx, req.ObjectID, keys)
+	if err != nil {
+		log.Printf("Warning: failed to read companions of %s: %v", req.ObjectID, err)
+		return nil
+	}
+	if track == nil {
+		track = &model.CompanionTrack{}
+	}
+
+	window := req.Timestamp / int64(cfg.Window.Seconds())
+	place := fingerprintID(req.Latitude, req.Longitude, cfg.PlaceMeters)
+
+	// Late uploads count towards the window they belong to but never
+	// open a new one
+	newWindow := track.Windows == 0 || window > track.LastWindow
+	if newWindow {
+		track.Windows++
+		track.LastWindow = window
+	}
+
+	companions := make(map[string]bool)
+	sources := make([]model.CompanionSource, 0, len(refs))
+	for i, ref := range refs {
+		src := known[keys[i]]
+		if src == nil {
+			src = &model.CompanionSource{
+				PointID:     ref.pointID,
+				PointType:   ref.pointType,
+				Places:      1,
+				FirstSeen:   req.Timestamp,
+				LastSeen:    req.Timestamp,
+				BaseWindows: track.Windows - 1,
+				LastPlace:   place,
+			}
+		}
+
+		if src.Observations == 0 || window != src.LastWindow {
+			src.Observations++
+			if window > src.LastWindow {
+				src.LastWindow = window
+			}
+		}
+		if place != src.LastPlace {
+			src.Places++
+			src.LastPlace = place
+		}
+		if req.Timestamp > src.LastSeen {
+			src.LastSeen = req.Timestamp
+		}
+		if req.Timestamp < src.FirstSeen {
+			src.FirstSeen = req.Timestamp
+		}
+
+		span := track.Windows - src.BaseWindows
+		src.Stability = float32(math.Min(float64(src.Observations)/math.Max(float64(span), 1), 1))
+
+		classified := int(src.Observations) >= cfg.MinObservations
+		src.IsCompanion = classified && int(src.Places) >= cfg.MinPlaces && float64(src.Stability) >= cfg.MinStability
+		src.IsStationary = classified && src.Places == 1
+
+		if src.IsCompanion {
+			companions[keys[i]] = true
+		}
+		sources = append(sources, *src)
+	}
+
+	if err := l.cache.SetCompanionState(ctx, req.ObjectID, track, sources, cfg.StaleAfter); err != nil {
+		log.Printf("Warning: failed to save companions of %s: %v", req.ObjectID, err)
+	}
+	if newWindow && track.Windows%companionPruneWindows == 0 {
+		l.pruneCompanions(ctx, req.ObjectID, req.Timestamp-int64(cfg.StaleAfter.Seconds()))
+	}
+	return companions
+}
+
+// pruneCompanions forgets sources of the object last seen before cutoff.
+func (l *LearningCore) pruneCompanions(ctx context.Context, objectID string, cutoff int64) {
+	all, err := l.cache.GetCompanions(ctx, objectID)
+	if err != nil {
+		log.Printf("Warning: failed to list companions of %s: %v", objectID, err)
+		return
+	}
+	var stale []string
+	for _, c := range all {
+		if c.LastSeen < cutoff {
+			stale = append(stale, model.CompanionKey(c.PointType, c.PointID))
+		}
+	}
+	if err := l.cache.DeleteCompanions(ctx, objectID, stale...); err != nil {
+		log.Printf("Warning: failed to prune companions of %s: %v", objectID, err)
+	}
+}
+
+// Companions returns the sources classified as travelling with the object,
+// optionally of one type only.
+func (l *LearningCore) Companions(ctx context.Context, objectID string, pointType model.PointType) ([]model.CompanionSource, error) {
+	all, err := l.cache.GetCompanions(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	companions := make([]model.CompanionSource, 0, len(all))
+	for _, c := range all {
+		if c.IsCompanion && (pointType == "" || c.PointType == pointType) {
+			companions = append(companions, c)
+		}
+	}
+	return companions, nil
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// newTestLearningCore returns a learning core sharing the config and the
+// in-memory Redis of a test validation core.
+func newTestLearningCore(t *testing.T) *LearningCore {
+	t.Helper()
+	v, _ := newTestCore(t)
+	return NewLearningCore(v.cache, v.cfg)
+}
+
+// learnAt returns a learn request east/north meters from the test origin
+// at ts.
+func learnAt(objectID string, east, north float64, ts int64) model.LearnRequest {
+	lat, lon := offsetLatLon(testLat, testLon, east, north)
+	return model.LearnRequest{ObjectID: objectID, Latitude: lat, Longitude: lon, Accuracy: 10, Timestamp: ts}
+}
+
+func TestObserveCompanions(t *testing.T) {
+	l := newTestLearningCore(t)
+	ctx := context.Background()
+	window := int64(l.cfg.Companion.Window.Seconds())
+	t0 := (time.Now().Unix()/window - 100) * window
+
+	// One report per step; place is 2 km per step east of the origin
+	type step struct {
+		window, place int
+		offset        int64 // seconds into the window
+		seen          bool  // the source under test is in the report
+	}
+	moving := func(n int, seen func(i int) bool) []step {
+		steps := make([]step, n)
+		for i := range steps {
+			steps[i] = step{window: i, place: i, seen: seen(i)}
+		}
+		return steps
+	}
+	always := func(int) bool { return true }
+
+	tests := []struct {
+		name         string
+		steps        []step
+		observations int32
+		places       int32
+		companion    bool
+		stationary   bool
+	}{
+		{
+			name:         "travels with the object",
+			steps:        moving(6, always),
+			observations: 6,
+			places:       6,
+			companion:    true,
+		},
+		{
+			name: "stays at one place",
+			steps: []step{
+				{0, 0, 0, true}, {1, 0, 0, true}, {2, 0, 0, true}, {3, 0, 0, true}, {4, 0, 0, true}, {5, 0, 0, true},
+			},
+			observations: 6,
+			places:       1,
+			stationary:   true,
+		},
+		{
+			name:         "seen in every other window",
+			steps:        moving(10, func(i int) bool { return i%2 == 0 }),
+			observations: 5,
+			places:       5,
+		},
+		{
+			name: "late upload counts once per window",
+			steps: []step{
+				{0, 0, 200, true}, {0, 0, 10, true}, {1, 1, 0, true}, {2, 2, 0, true}, {3, 3, 0, true}, {3, 3, 5, true}, {4, 4, 0, true},
+			},
+			observations: 5,
+			places:       5,
+			companion:    true,
+		},
+		{
+			name:         "too few windows to classify",
+			steps:        moving(4, always),
+			observations: 4,
+			places:       4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objectID := tt.name
+			for _, s := range tt.steps {
+				req := learnAt(objectID, float64(s.place)*2000, 0, t0+int64(s.window)*window+s.offset)
+				// A stationary AP at each place keeps the object's windows counted
+				req.Wifi = []model.WifiAP{{BSSID: fmt.Sprintf("00:11:22:33:44:%02x", s.place)}}
+				if s.seen {
+					req.Bluetooth = []model.BluetoothDev{{MAC: "c0:00:00:00:00:01"}}
+				}
+				l.observeCompanions(ctx, &req)
+			}
+
+			all, err := l.cache.GetCompanions(ctx, objectID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got *model.CompanionSource
+			for i := range all {
+				if all[i].PointType == model.PointTypeBT {
+					got = &all[i]
+				}
+			}
+			if got == nil {
+				t.Fatal("source not recorded")
+			}
+			if got.Observations != tt.observations || got.Places != tt.places {
+				t.Errorf("observations/places = %d/%d, want %d/%d", got.Observations, got.Places, tt.observations, tt.places)
+			}
+			if got.IsCompanion != tt.companion || got.IsStationary != tt.stationary {
+				t.Errorf("companion/stationary = %v/%v (stability %.2f), want %v/%v",
+					got.IsCompanion, got.IsStationary, got.Stability, tt.companion, tt.stationary)
+			}
+
+			companions, err := l.Companions(ctx, objectID, model.PointTypeBT)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (len(companions) == 1) != tt.companion {
+				t.Errorf("Companions = %+v, want companion %v", companions, tt.companion)
+			}
+		})
+	}
+}
+
+func TestLearnSkipsCompanions(t *testing.T) {
+	l := newTestLearningCore(t)
+	ctx := context.Background()
+	window := int64(l.cfg.Companion.Window.Seconds())
+	t0 := (time.Now().Unix()/window - 100) * window
+
+	var resp *model.LearnResponse
+	for i := 0; i < 6; i++ {
+		req := learnAt("van-1", float64(i)*2000, 0, t0+int64(i)*window)
+		req.Bluetooth = []model.BluetoothDev{{MAC: "c0:00:00:00:00:01"}}
+		var err error
+		if resp, err = l.Learn(ctx, &req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(resp.RandomSources) != 1 || len(resp.StationarySources) != 0 {
+		t.Errorf("stationary/random = %v/%v, want the companion as random", resp.StationarySources, resp.RandomSources)
+	}
+	bt, err := l.cache.GetBT(ctx, "c0:00:00:00:00:01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bt == nil || bt.ObsCount >= 6 {
+		t.Errorf("cached BLE = %+v, want it no longer learned once a companion", bt)
+	}
+}
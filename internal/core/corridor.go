@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"coordinate-validator/internal/cache"
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Transit Corridors
+// ============================================
+
+// Max positions sampled along a jump when checking corridor coverage
+const maxCorridorSamples = 500
+
+// CorridorRegistry holds ferry, train and flight corridors managed through
+// the admin API. Corridors are cached in memory and reloaded from Redis
+// every RefreshInterval.
+type CorridorRegistry struct {
+	cache     *cache.RedisCache
+	cfg       *config.CorridorConfig
+	corridors *snapshot[[]indexedCorridor]
+}
+
+type indexedCorridor struct {
+	corridor model.Corridor
+
+	// Bounding box of the path expanded by the buffer
+	minLat, minLon, maxLat, maxLon float64
+}
+
+func NewCorridorRegistry(cache *cache.RedisCache, cfg *config.CorridorConfig) *CorridorRegistry {
+	r := &CorridorRegistry{
+		cache: cache,
+		cfg:   cfg,
+	}
+	r.corridors = newSnapshot("corridors", cfg.RefreshInterval, r.load)
+	return r
+}
+
+func (r *CorridorRegistry) load(ctx context.Context) ([]indexedCorridor, error) {
+	corridors, err := r.cache.ListCorridors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := make([]indexedCorridor, 0, len(corridors))
+	for _, c := range corridors {
+		indexed = append(indexed, newIndexedCorridor(c))
+	}
+	return indexed, nil
+}
+
+// Match returns a corridor covering the jump from point 1 to point 2 whose
+// speed limit allows speed, or nil.
+func (r *CorridorRegistry) Match(ctx context.Context, lat1, lon1, lat2, lon2, speed float64) *model.Corridor {
+	corridors := r.corridors.get(ctx)
+	for i := range corridors {
+		ic := &corridors[i]
+		if speed > ic.corridor.MaxSpeedKmH {
+			continue
+		}
+		if ic.covers(lat1, lon1, lat2, lon2) {
+			c := ic.corridor
+			return &c
+		}
+	}
+	return nil
+}
+
+func newIndexedCorridor(c model.Corridor) indexedCorridor {
+	ic := indexedCorridor{corridor: c, minLat: 90, minLon: 180, maxLat: -90, maxLon: -180}
+	for _, p := range c.Path {
+		ic.minLat = math.Min(ic.minLat, p.Latitude)
+		ic.maxLat = math.Max(ic.maxLat, p.Latitude)
+		ic.minLon = math.Min(ic.minLon, p.Longitude)
+		ic.maxLon = math.Max(ic.maxLon, p.Longitude)
+	}
+
+	dLat := c.BufferMeters / earthRadiusMeters * 180 / math.Pi
+	dLon := dLat / math.Max(math.Cos(toRad(math.Max(math.Abs(ic.minLat), math.Abs(ic.maxLat)))), 0.01)
+	ic.minLat -= dLat
+	ic.maxLat += dLat
+	ic.minLon -= dLon
+	ic.maxLon += dLon
+	return ic
+}
+
+// covers reports whether both endpoints and the straight segment between
+// them stay within the corridor buffer.
+func (ic *indexedCorridor) covers(lat1, lon1, lat2, lon2 float64) bool {
+	if !ic.inBounds(lat1, lon1) || !ic.inBounds(lat2, lon2) {
+		return false
+	}
+
+	buffer := ic.corridor.BufferMeters
+	length := HaversineDistance(lat1, lon1, lat2, lon2) * 1000
+	n := int(math.Min(math.Ceil(length/buffer), maxCorridorSamples))
+	if n < 1 {
+		n = 1
+	}
+	for i := 0; i <= n; i++ {
+		t := float64(i) / float64(n)
+		lat := lat1 + (lat2-lat1)*t
+		lon := lon1 + (lon2-lon1)*t
+		if distanceToPath(ic.corridor.Path, lat, lon) > buffer {
+			return false
+		}
+	}
+	return true
+}
+
+func (ic *indexedCorridor) inBounds(lat, lon float64) bool {
+	return lat >= ic.minLat && lat <= ic.maxLat && lon >= ic.minLon && lon <= ic.maxLon
+}
+
+// distanceToPath returns the distance in meters from a point to a polyline,
+// measured in a local tangent plane at the point.
+func distanceToPath(path []model.GeoPoint, lat, lon float64) float64 {
+	best := math.Inf(1)
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		best = math.Min(best, segmentDistance(lat, lon, a.Latitude, a.Longitude, b.Latitude, b.Longitude))
+	}
+	return best
+}
+
+// segmentDistance returns the distance in meters from a point to the segment
+// a-b, measured in a local tangent plane at the point.
+func segmentDistance(lat, lon, aLat, aLon, bLat, bLon float64) float64 {
+	ax, ay := localOffset(lat, lon, aLat, aLon)
+	bx, by := localOffset(lat, lon, bLat, bLon)
+
+	// Closest point of segment a-b to the origin
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l2 := dx*dx + dy*dy; l2 > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l2))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// ============================================
+// Management
+// ============================================
+
+func (r *CorridorRegistry) List(ctx context.Context) ([]model.Corridor, error) {
+	return r.cache.ListCorridors(ctx)
+}
+
+func (r *CorridorRegistry) Set(ctx context.Context, corridor *model.Corridor) error {
+	if corridor.ID == "" {
+		return fmt.Errorf("corridor id is required")
+	}
+	if len(corridor.Path) < 2 {
+		return fmt.Errorf("corridor %q: path needs at least 2 points", corridor.ID)
+	}
+	if corridor.BufferMeters <= 0 || corridor.MaxSpeedKmH <= 0 {
+		return fmt.Errorf("corridor %q: buffer and max speed must be positive", corridor.ID)
+	}
+	if err := r.cache.SetCorridor(ctx, corridor); err != nil {
+		return err
+	}
+	r.corridors.invalidate()
+	return nil
+}
+
+func (r *CorridorRegistry) Delete(ctx context.Context, id string) error {
+	if err := r.cache.DeleteCorridor(ctx, id); err != nil {
+		return err
+	}
+	r.corridors.invalidate()
+	return nil
+}
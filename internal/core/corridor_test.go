@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestCorridorRegistryChanges(t *testing.T) {
+	v, _ := newTestCore(t)
+	r := v.corridors
+	ctx := context.Background()
+
+	// Ferry line 10 km east of the origin
+	east := func(m float64) (float64, float64) { return offsetLatLon(testLat, testLon, m, 0) }
+	lat1, lon1 := east(0)
+	lat2, lon2 := east(10000)
+
+	// Loaded before the corridor exists
+	if c := r.Match(ctx, lat1, lon1, lat2, lon2, 40); c != nil {
+		t.Fatalf("no corridors: matched %q", c.ID)
+	}
+
+	ferry := model.Corridor{
+		ID:           "ferry",
+		Path:         []model.GeoPoint{{Latitude: lat1, Longitude: lon1}, {Latitude: lat2, Longitude: lon2}},
+		BufferMeters: 500,
+		MaxSpeedKmH:  60,
+	}
+	if err := r.Set(ctx, &ferry); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		east  float64
+		speed float64
+		want  bool
+	}{
+		{"along the path", 10000, 40, true},
+		{"faster than the corridor allows", 10000, 80, false},
+		{"beyond the path end", 12000, 40, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lon := east(tt.east)
+			if got := r.Match(ctx, lat1, lon1, lat, lon, tt.speed) != nil; got != tt.want {
+				t.Errorf("matched = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := r.Delete(ctx, "ferry"); err != nil {
+		t.Fatal(err)
+	}
+	if c := r.Match(ctx, lat1, lon1, lat2, lon2, 40); c != nil {
+		t.Errorf("after Delete: matched %q", c.ID)
+	}
+}
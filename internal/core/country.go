@@ -0,0 +1,160 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// MCC / Country Consistency
+// ============================================
+
+// CountryRegistry resolves positions to countries from an offline boundary
+// dataset and cells to countries by MCC. Both are loaded once at startup;
+// without a boundary file the check is disabled.
+type CountryRegistry struct {
+	cfg    *config.CountryConfig
+	mcc    map[uint32][]string
+	index  *geoIndex
+	byCode map[string][]int // country code -> zone indexes
+}
+
+func NewCountryRegistry(cfg *config.CountryConfig) *CountryRegistry {
+	r := &CountryRegistry{
+		cfg: cfg,
+		mcc: mccCountries,
+	}
+	if cfg.MCCFile != "" {
+		table, err := LoadMCCFile(cfg.MCCFile)
+		if err != nil {
+			log.Printf("Warning: failed to load MCC table, using built-in: %v", err)
+		} else {
+			r.mcc = table
+		}
+	}
+	if cfg.BoundariesFile == "" {
+		return r
+	}
+
+	countries, err := LoadCountryFile(cfg.BoundariesFile)
+	if err != nil {
+		log.Printf("Warning: failed to load country boundaries: %v", err)
+		return r
+	}
+	r.index = newGeoIndex(countries, cfg.IndexCellDegrees)
+	r.byCode = make(map[string][]int)
+	for i, z := range r.index.zones {
+		r.byCode[z.zone.ID] = append(r.byCode[z.zone.ID], i)
+	}
+	return r
+}
+
+// Evaluate compares the country of the position with the countries of the
+// reported cells' MCCs. It returns nothing when the check can't be made:
+// no boundary dataset, or no cell with a known MCC.
+func (r *CountryRegistry) Evaluate(cells []model.CellTower, lat, lon float64) ([]model.CheckResult, float64) {
+	if r.index == nil {
+		return nil, 1
+	}
+
+	expected := make(map[string]bool)
+	mccSet := make(map[uint32]bool)
+	for _, c := range cells {
+		for _, code := range r.mcc[c.MCC] {
+			expected[code] = true
+			mccSet[c.MCC] = true
+		}
+	}
+	if len(expected) == 0 {
+		return nil, 1
+	}
+	mccs := make([]string, 0, len(mccSet))
+	for m := range mccSet {
+		mccs = append(mccs, fmt.Sprint(m))
+	}
+	sort.Strings(mccs)
+	subject := strings.Join(mccs, ",")
+
+	var here []string
+	for _, i := range r.index.containing(lat, lon) {
+		code := r.index.zones[i].zone.ID
+		if expected[code] {
+			return []model.CheckResult{{
+				Check:   model.CheckMCCCountry,
+				Code:    model.CheckCodeOK,
+				Passed:  true,
+				Subject: subject,
+			}}, 1
+		}
+		here = append(here, code)
+	}
+
+	// Cells reach across borders: accept positions near a cell's country
+	tolerance := r.cfg.BorderToleranceMeters
+	distance := math.Inf(1)
+	for code := range expected {
+		for _, i := range r.byCode[code] {
+			distance = math.Min(distance, r.index.zones[i].borderDistance(lat, lon, tolerance))
+		}
+	}
+	if distance <= tolerance {
+		return []model.CheckResult{{
+			Check:     model.CheckMCCCountry,
+			Code:      model.CheckCodeNearBorder,
+			Passed:    true,
+			Value:     distance,
+			Threshold: tolerance,
+			Subject:   subject,
+		}}, 1
+	}
+
+	location := "outside known countries"
+	if len(here) > 0 {
+		location = "in " + strings.Join(here, ", ")
+	}
+	check := model.CheckResult{
+		Check:     model.CheckMCCCountry,
+		Code:      model.CheckCodeCountryMismatch,
+		Threshold: tolerance,
+		Message:   fmt.Sprintf("Position is %s but visible cells are from MCC %s", location, subject),
+		Subject:   subject,
+	}
+	if !math.IsInf(distance, 1) {
+		check.Value = distance
+	}
+	return []model.CheckResult{check}, 1 - r.cfg.MismatchPenalty
+}
+
+// borderDistance returns the distance in meters from the point to the
+// nearest ring of the zone, or +Inf if it is farther than limit.
+func (iz *indexedZone) borderDistance(lat, lon, limit float64) float64 {
+	dLat := limit / earthRadiusMeters * 180 / math.Pi
+	dLon := dLat / math.Max(math.Cos(toRad(lat)), 0.01)
+	if lat < iz.minLat-dLat || lat > iz.maxLat+dLat || lon < iz.minLon-dLon || lon > iz.maxLon+dLon {
+		return math.Inf(1)
+	}
+
+	best := math.Inf(1)
+	for _, p := range iz.zone.Polygons {
+		for _, ring := range p {
+			for i := 1; i < len(ring); i++ {
+				a, b := ring[i-1], ring[i]
+				// Skip segments whose both ends are clearly out of range
+				if math.Abs(a[1]-lat) > dLat && math.Abs(b[1]-lat) > dLat && (a[1]-lat)*(b[1]-lat) > 0 {
+					continue
+				}
+				best = math.Min(best, segmentDistance(lat, lon, a[1], a[0], b[1], b[0]))
+			}
+		}
+	}
+	if best > limit {
+		return math.Inf(1)
+	}
+	return best
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Source Position Estimation
+// ============================================
+//
+// Each source keeps a bounded uniform sample (reservoir) of the positions it
+// was learned at, weighted by fix accuracy and signal strength. Its position
+// is the weighted geometric median of the sample: unlike a running average
+// it doesn't depend on the order of the fixes, and a single bad fix can't
+// drag it. How far the sample scatters gives the coverage radius and tempers
+// the confidence.
+
+const (
+	medianIterations = 50
+	medianTolerance  = 0.01 // meters
+)
+
+// sampleWeight weighs a learned position by the inverse of its expected
+// squared error: the fix accuracy and the distance to the source implied by
+// its signal. A precise fix next to a strong AP outweighs a coarse one at
+// the edge of its range.
+func sampleWeight(accuracy float32, rng distanceRange) float64 {
+	a := math.Max(float64(accuracy), 1)
+	d := math.Max(rng.expected, 1)
+	return 1 / (a*a + d*d)
+}
+
+// addSample offers a learned position to the reservoir: it is kept with
+// probability size/offered, replacing a random one, so the reservoir stays
+// a uniform sample of every position offered.
+func (l *LearningCore) addSample(summary model.PositionSummary, sample model.PositionSample) model.PositionSummary {
+	size := l.cfg.Learning.ReservoirSize
+	summary.Offered++
+	reservoir := append([]model.PositionSample(nil), summary.Reservoir...)
+	if len(reservoir) < size {
+		reservoir = append(reservoir, sample)
+	} else if j := rand.Int63n(summary.Offered); j < int64(size) {
+		reservoir[j] = sample
+	}
+	summary.Reservoir = reservoir
+	return summary
+}
+
+// seedSummary starts the reservoir of an entry cached before it was kept
+// from its learned position.
+func seedSummary(summary model.PositionSummary, lat, lon, weight float64) model.PositionSummary {
+	if len(summary.Reservoir) == 0 {
+		summary.Reservoir = []model.PositionSample{{Latitude: lat, Longitude: lon, Weight: weight}}
+		summary.Offered = 1
+	}
+	return summary
+}
+
+// estimateSource returns the position of a source from its reservoir and
+// updates the spread and coverage radius around it.
+func (l *LearningCore) estimateSource(summary model.PositionSummary) (float64, float64, model.PositionSummary) {
+	lat, lon := geometricMedian(summary.Reservoir)
+
+	dists := make([]float64, len(summary.Reservoir))
+	weights := make([]float64, len(summary.Reservoir))
+	for i, s := range summary.Reservoir {
+		dists[i] = HaversineDistance(lat, lon, s.Latitude, s.Longitude) * 1000
+		weights[i] = s.Weight
+	}
+	summary.Spread = weightedQuantile(dists, weights, 0.5)
+
+	// Too few positions to tell how far the source is heard
+	summary.CoverageRadius = 0
+	if int64(len(summary.Reservoir)) >= l.cfg.Learning.MinObservations {
+		summary.CoverageRadius = weightedQuantile(dists, weights, l.cfg.Learning.CoverageQuantile)
+	}
+	return lat, lon, summary
+}
+
+// spreadScale returns the spread at which a source's confidence is halved.
+func (l *LearningCore) spreadScale(pointType model.PointType) float64 {
+	cfg := &l.cfg.Learning
+	switch pointType {
+	case model.PointTypeWifi:
+		return cfg.SpreadWifiMeters
+	case model.PointTypeBT:
+		return cfg.SpreadBLEMeters
+	case model.PointTypeCell:
+		return cfg.SpreadCellMeters
+	}
+	return 0
+}
+
+// geometricMedian returns the weighted geometric median of the samples, the
+// point minimizing the weighted sum of distances to them (Weiszfeld's
+// algorithm, in a local plane around their weighted mean).
+func geometricMedian(samples []model.PositionSample) (float64, float64) {
+	var sumW, lat0, lon0 float64
+	for _, s := range samples {
+		w := math.Max(s.Weight, 0)
+		sumW += w
+		lat0 += s.Latitude * w
+		lon0 += s.Longitude * w
+	}
+	if sumW == 0 {
+		return samples[0].Latitude, samples[0].Longitude
+	}
+	lat0 /= sumW
+	lon0 /= sumW
+
+	mLat := earthRadiusMeters * math.Pi / 180
+	mLon := mLat * math.Max(math.Cos(toRad(lat0)), 0.01)
+	xs := make([]float64, len(samples))
+	ys := make([]float64, len(samples))
+	for i, s := range samples {
+		xs[i] = (s.Longitude - lon0) * mLon
+		ys[i] = (s.Latitude - lat0) * mLat
+	}
+
+	// Start from the weighted mean, i.e. the origin
+	var x, y float64
+	for iter := 0; iter < medianIterations; iter++ {
+		var nx, ny, den float64
+		for i, s := range samples {
+			d := math.Max(math.Hypot(xs[i]-x, ys[i]-y), medianTolerance)
+			w := math.Max(s.Weight, 0) / d
+			nx += xs[i] * w
+			ny += ys[i] * w
+			den += w
+		}
+		nx /= den
+		ny /= den
+		moved := math.Hypot(nx-x, ny-y)
+		x, y = nx, ny
+		if moved < medianTolerance {
+			break
+		}
+	}
+	return lat0 + y/mLat, lon0 + x/mLon
+}
+
+// weightedQuantile returns the smallest value below which at least q of the
+// total weight lies.
+func weightedQuantile(values, weights []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	idx := make([]int, len(values))
+	var total float64
+	for i := range idx {
+		idx[i] = i
+		total += weights[i]
+	}
+	sort.Slice(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	var cum float64
+	for _, i := range idx {
+		cum += weights[i]
+		if cum >= q*total {
+			return values[i]
+		}
+	}
+	return values[idx[len(idx)-1]]
+}
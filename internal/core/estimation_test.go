@@ -0,0 +1,93 @@
+package core
+
+import (
+	"testing"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestGeometricMedian(t *testing.T) {
+	const lat, lon = 55.75, 37.62
+	at := func(east, north, weight float64) model.PositionSample {
+		la, lo := offsetLatLon(lat, lon, east, north)
+		return model.PositionSample{Latitude: la, Longitude: lo, Weight: weight}
+	}
+
+	tests := []struct {
+		name        string
+		samples     []model.PositionSample
+		east, north float64
+		tolMeters   float64
+	}{
+		{
+			name:      "single sample",
+			samples:   []model.PositionSample{at(30, -20, 1)},
+			east:      30,
+			north:     -20,
+			tolMeters: 0.01,
+		},
+		{
+			name:      "no weight falls back to the first sample",
+			samples:   []model.PositionSample{at(10, 0, 0), at(500, 500, 0)},
+			east:      10,
+			tolMeters: 0.01,
+		},
+		{
+			name:      "symmetric samples",
+			samples:   []model.PositionSample{at(-100, -100, 1), at(100, -100, 1), at(100, 100, 1), at(-100, 100, 1)},
+			tolMeters: 0.1,
+		},
+		{
+			name:      "outlier doesn't drag the median",
+			samples:   []model.PositionSample{at(0, 0, 1), at(0, 0, 1), at(0, 0, 1), at(5000, 0, 1)},
+			tolMeters: 1,
+		},
+		{
+			name:      "heavier sample wins",
+			samples:   []model.PositionSample{at(0, 0, 3), at(200, 0, 1)},
+			tolMeters: 1,
+		},
+		{
+			name:      "negative weight counts as none",
+			samples:   []model.PositionSample{at(0, 0, 1), at(0, 0, 1), at(300, 300, -5)},
+			tolMeters: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLat, gotLon := geometricMedian(tt.samples)
+			wantLat, wantLon := offsetLatLon(lat, lon, tt.east, tt.north)
+			if d := HaversineDistance(gotLat, gotLon, wantLat, wantLon) * 1000; d > tt.tolMeters {
+				t.Errorf("median is %.3f m from want, tolerance %.3f m", d, tt.tolMeters)
+			}
+		})
+	}
+}
+
+func TestWeightedQuantile(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []float64
+		weights []float64
+		q       float64
+		want    float64
+	}{
+		{"empty", nil, nil, 0.5, 0},
+		{"equal weights median", []float64{1, 2, 3, 4}, []float64{1, 1, 1, 1}, 0.5, 2},
+		{"unsorted values", []float64{4, 1, 3, 2}, []float64{1, 1, 1, 1}, 0.5, 2},
+		{"weight shifts the median", []float64{10, 20, 30}, []float64{1, 1, 8}, 0.5, 30},
+		{"upper quantile", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, 0.9, 9},
+		{"zero quantile is the smallest", []float64{5, 3, 9}, []float64{1, 1, 1}, 0, 3},
+		{"full quantile is the largest", []float64{5, 3, 9}, []float64{1, 1, 1}, 1, 9},
+		{"zero weight never reached", []float64{1, 100}, []float64{1, 0}, 0.9, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weightedQuantile(tt.values, tt.weights, tt.q); got != tt.want {
+				t.Errorf("weightedQuantile(%v, %v, %v) = %v, want %v", tt.values, tt.weights, tt.q, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Source Exclusion
+// ============================================
+//
+// Phone hotspots, car head units and devices with rotating addresses go
+// wherever their owner goes, so any position learned for them is wrong the
+// next time they are seen. Well-known hotspot SSIDs and rotating BLE
+// addresses are never learned. Other sources are listed while learning
+// classifies them MOBILE; an AP with a locally administered BSSID is then
+// listed as a randomized MAC, since mesh and enterprise APs use such BSSIDs
+// too and only the mobility tells them apart. GetExcludedPoints lists them
+// all.
+
+// compileSSIDPatterns compiles the SSID patterns as case-insensitive
+// regular expressions, skipping invalid ones.
+func compileSSIDPatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			log.Printf("Warning: invalid SSID pattern %q: %v", p, err)
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// wifiExclusion returns why an AP must not be learned, "" if it may be.
+func (l *LearningCore) wifiExclusion(w *model.WifiAP) model.ExclusionReason {
+	if w.SSID == "" {
+		return ""
+	}
+	for _, re := range l.ssidPatterns {
+		if re.MatchString(w.SSID) {
+			return model.ExclusionHotspotSSID
+		}
+	}
+	return ""
+}
+
+// btExclusion returns why a Bluetooth device must not be learned, "" if it
+// may be.
+func (l *LearningCore) btExclusion(b *model.BluetoothDev) model.ExclusionReason {
+	if l.cfg.Exclusion.RandomizedMAC && isRotatingBLEAddress(b.MAC) {
+		return model.ExclusionRandomizedMAC
+	}
+	return ""
+}
+
+// isRotatingBLEAddress reports whether the top two bits of a BLE address
+// mark a resolvable (01) or non-resolvable (00) private address, which
+// changes every few minutes. Static random addresses (11) are fixed.
+func isRotatingBLEAddress(mac string) bool {
+	if len(mac) < 2 {
+		return false
+	}
+	octet, err := strconv.ParseUint(mac[:2], 16, 8)
+	return err == nil && octet>>6 <= 1
+}
+
+// isLocallyAdministered reports whether the MAC has the locally
+// administered bit set, as randomized and soft AP addresses do.
+func isLocallyAdministered(mac string) bool {
+	if len(mac) < 2 {
+		return false
+	}
+	octet, err := strconv.ParseUint(mac[:2], 16, 8)
+	return err == nil && octet&0x02 != 0
+}
+
+// recordExclusion stores the excluded source. Unless keepLearned is set,
+// any position learned for it before it was recognised is dropped.
+func (l *LearningCore) recordExclusion(ctx context.Context, pointType model.PointType, pointID, ssid string, reason model.ExclusionReason, keepLearned bool) {
+	err := l.cache.ExcludeSource(ctx, &model.ExcludedSource{
+		PointID:    pointID,
+		PointType:  pointType,
+		Reason:     reason,
+		SSID:       ssid,
+		DetectedAt: time.Now().Unix(),
+	}, l.cfg.Exclusion.MaxRecorded, !keepLearned)
+	if err != nil {
+		log.Printf("Warning: failed to record excluded %s %s: %v", pointType, pointID, err)
+	}
+}
+
+// updateMobileExclusion lists a source when learning starts classifying it
+// MOBILE and unlists it once it is no longer, e.g. when it is re-learned
+// after a move. The source stays learned so its class can change.
+func (l *LearningCore) updateMobileExclusion(ctx context.Context, pointType model.PointType, pointID string, prev, next model.SourceMobility) {
+	switch {
+	case next == model.SourceMobilityMobile && prev != model.SourceMobilityMobile:
+		reason := model.ExclusionMobile
+		if pointType == model.PointTypeWifi && l.cfg.Exclusion.RandomizedMAC && isLocallyAdministered(pointID) {
+			reason = model.ExclusionRandomizedMAC
+		}
+		l.recordExclusion(ctx, pointType, pointID, "", reason, true)
+	case prev == model.SourceMobilityMobile && next != model.SourceMobilityMobile:
+		if err := l.cache.IncludeSource(ctx, pointType, pointID); err != nil {
+			log.Printf("Warning: failed to unlist excluded %s %s: %v", pointType, pointID, err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestIsRotatingBLEAddress(t *testing.T) {
+	tests := []struct {
+		mac  string
+		want bool
+	}{
+		{"c0:1a:7d:00:00:01", false}, // static random
+		{"ff:00:00:00:00:01", false}, // static random
+		{"40:1a:7d:00:00:01", true},  // resolvable private
+		{"7f:00:00:00:00:01", true},  // resolvable private
+		{"3f:00:00:00:00:01", true},  // non-resolvable private
+		{"02:00:00:00:00:01", true},  // non-resolvable private, LA bit set
+		{"80:00:00:00:00:01", false}, // reserved
+		{"zz:00:00:00:00:01", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mac, func(t *testing.T) {
+			if got := isRotatingBLEAddress(tt.mac); got != tt.want {
+				t.Errorf("isRotatingBLEAddress(%q) = %v, want %v", tt.mac, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWifiExclusion(t *testing.T) {
+	l := newTestLearningCore(t)
+
+	tests := []struct {
+		ssid  string
+		bssid string
+		want  model.ExclusionReason
+	}{
+		{"iPhone (Anna)", "00:1a:2b:00:00:01", model.ExclusionHotspotSSID},
+		{"AndroidAP_5f2c", "00:1a:2b:00:00:01", model.ExclusionHotspotSSID},
+		{"DIRECT-a4-Galaxy S21", "00:1a:2b:00:00:01", model.ExclusionHotspotSSID},
+		{"androidap", "00:1a:2b:00:00:01", model.ExclusionHotspotSSID},
+		{"Cafe iPhone Repair", "00:1a:2b:00:00:01", ""},
+		{"Toyota Service Center", "00:1a:2b:00:00:01", ""},
+		{"Galaxy Cinema", "00:1a:2b:00:00:01", ""},
+		{"CorpNet", "02:1a:2b:00:00:01", ""}, // locally administered BSSID alone
+		{"", "02:1a:2b:00:00:01", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ssid, func(t *testing.T) {
+			if got := l.wifiExclusion(&model.WifiAP{SSID: tt.ssid, BSSID: tt.bssid}); got != tt.want {
+				t.Errorf("wifiExclusion(%q, %s) = %q, want %q", tt.ssid, tt.bssid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLearnExclusion(t *testing.T) {
+	l := newTestLearningCore(t)
+	ctx := context.Background()
+	t0 := time.Now().Unix() - 24*3600
+
+	settled := []float64{0, 10, -10, 5, -5}
+	wandering := []float64{0, 300, -300, 250, -250}
+
+	tests := []struct {
+		name    string
+		wifi    *model.WifiAP
+		ble     *model.BluetoothDev
+		east    []float64 // learned positions, an hour apart
+		reason  model.ExclusionReason
+		learned bool // a position stays cached
+	}{
+		{
+			name:   "phone hotspot",
+			wifi:   &model.WifiAP{SSID: "iPhone (Anna)", BSSID: "00:1a:2b:00:00:01", RSSI: -60},
+			east:   settled,
+			reason: model.ExclusionHotspotSSID,
+		},
+		{
+			name:    "mesh AP with a locally administered BSSID",
+			wifi:    &model.WifiAP{SSID: "CorpNet", BSSID: "02:1a:2b:00:00:02", RSSI: -60},
+			east:    settled,
+			learned: true,
+		},
+		{
+			name:    "mobile AP with a locally administered BSSID",
+			wifi:    &model.WifiAP{SSID: "Guest", BSSID: "02:1a:2b:00:00:03", RSSI: -60},
+			east:    wandering,
+			reason:  model.ExclusionRandomizedMAC,
+			learned: true,
+		},
+		{
+			name:    "mobile AP",
+			wifi:    &model.WifiAP{SSID: "Guest", BSSID: "00:1a:2b:00:00:04", RSSI: -60},
+			east:    wandering,
+			reason:  model.ExclusionMobile,
+			learned: true,
+		},
+		{
+			name:   "rotating BLE address",
+			ble:    &model.BluetoothDev{MAC: "40:1a:7d:00:00:05", RSSI: -70},
+			east:   settled,
+			reason: model.ExclusionRandomizedMAC,
+		},
+		{
+			name:    "static random BLE address",
+			ble:     &model.BluetoothDev{MAC: "c0:1a:7d:00:00:06", RSSI: -70},
+			east:    settled,
+			learned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pointType, pointID := model.PointTypeWifi, ""
+			if tt.wifi != nil {
+				pointID = tt.wifi.BSSID
+			} else {
+				pointType, pointID = model.PointTypeBT, tt.ble.MAC
+			}
+
+			for j, east := range tt.east {
+				// Different probes, or the source would follow one as a companion
+				req := learnAt(fmt.Sprintf("probe-%d", j), east, 0, t0+int64(j)*3600)
+				if tt.wifi != nil {
+					req.Wifi = []model.WifiAP{*tt.wifi}
+				} else {
+					req.Bluetooth = []model.BluetoothDev{*tt.ble}
+				}
+				if _, err := l.Learn(ctx, &req); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			excluded, err := l.cache.ListExcludedSources(ctx, pointType, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var reason model.ExclusionReason
+			for _, ex := range excluded {
+				if ex.PointID == pointID {
+					reason = ex.Reason
+				}
+			}
+			if reason != tt.reason {
+				t.Errorf("excluded as %q, want %q", reason, tt.reason)
+			}
+
+			learned := false
+			if pointType == model.PointTypeWifi {
+				cached, _ := l.cache.GetWifi(ctx, pointID)
+				learned = cached != nil
+			} else {
+				cached, _ := l.cache.GetBT(ctx, pointID)
+				learned = cached != nil
+			}
+			if learned != tt.learned {
+				t.Errorf("learned = %v, want %v", learned, tt.learned)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// WiFi Fingerprints
+// ============================================
+//
+// Learning records which access points are seen together within each grid
+// cell. Validation compares a scan with the fingerprints sharing its APs: a
+// scan whose known APs never appeared together is suspicious even if each
+// AP is individually known, and the best matching fingerprints (kNN) give a
+// location to compare the reported fix with.
+
+// fingerprintID returns the grid cell of a position. The cell size is part
+// of the ID so cells of different sizes never mix.
+func fingerprintID(lat, lon, cellMeters float64) string {
+	dLat := cellMeters / earthRadiusMeters * 180 / math.Pi
+	row := math.Floor(lat / dLat)
+	dLon := dLat / math.Max(math.Cos(toRad((row+0.5)*dLat)), 0.01)
+	col := math.Floor(lon / dLon)
+	return fmt.Sprintf("%.0f:%.0f:%.0f", cellMeters, row, col)
+}
+
+// recordFingerprint adds the scan to the fingerprint of the grid cell at the
+// learned position. Only the APs learned from the scan are given, so those
+// travelling with the object or excluded are left out.
+func (l *LearningCore) recordFingerprint(ctx context.Context, req *model.LearnRequest, wifi []model.WifiAP) {
+	cfg := &l.cfg.Fingerprint
+	if len(wifi) == 0 || cfg.CellMeters <= 0 {
+		return
+	}
+
+	id := fingerprintID(req.Latitude, req.Longitude, cfg.CellMeters)
+	fp, err := l.cache.GetFingerprint(ctx, id)
+	if err != nil {
+		log.Printf("Warning: failed to read fingerprint %s: %v", id, err)
+		return
+	}
+	if fp == nil {
+		fp = &model.WifiFingerprint{ID: id, APs: make(map[string]model.FingerprintAP)}
+	}
+
+	n := float64(fp.Scans)
+	fp.Latitude = (fp.Latitude*n + req.Latitude) / (n + 1)
+	fp.Longitude = (fp.Longitude*n + req.Longitude) / (n + 1)
+	fp.Scans++
+
+	seen := make(map[string]bool, len(wifi))
+	for _, w := range wifi {
+		if seen[w.BSSID] {
+			continue
+		}
+		seen[w.BSSID] = true
+
+		ap := fp.APs[w.BSSID]
+		ap.Seen++
+		if rssi := sourceRSSI(w.RSSI, w.EID); rssi != 0 {
+			ap.RSSI = (ap.RSSI*float64(ap.RSSISamples) + float64(rssi)) / float64(ap.RSSISamples+1)
+			ap.RSSISamples++
+		}
+		fp.APs[w.BSSID] = ap
+	}
+	dropped := pruneFingerprint(fp, cfg.MaxAPs)
+	fp.UpdatedAt = time.Now()
+
+	if err := l.cache.SetFingerprint(ctx, fp, dropped); err != nil {
+		log.Printf("Warning: failed to save fingerprint %s: %v", id, err)
+	}
+}
+
+// pruneFingerprint drops the least seen APs beyond maxAPs and returns them.
+func pruneFingerprint(fp *model.WifiFingerprint, maxAPs int) []string {
+	if maxAPs <= 0 || len(fp.APs) <= maxAPs {
+		return nil
+	}
+	bssids := make([]string, 0, len(fp.APs))
+	for b := range fp.APs {
+		bssids = append(bssids, b)
+	}
+	sort.Slice(bssids, func(i, j int) bool {
+		return fp.APs[bssids[i]].Seen < fp.APs[bssids[j]].Seen
+	})
+	dropped := bssids[:len(bssids)-maxAPs]
+	for _, b := range dropped {
+		delete(fp.APs, b)
+	}
+	return dropped
+}
+
+type fingerprintScore struct {
+	fp         *model.WifiFingerprint
+	similarity float64
+	common     int
+}
+
+// matchFingerprint scores the scan against learned fingerprints. It returns
+// nil when there is too little data to judge.
+func (v *ValidationCore) matchFingerprint(ctx context.Context, req *model.CoordinateRequest) (*model.CheckResult, float64) {
+	cfg := &v.cfg.Fingerprint
+
+	scan := make(map[string]int32, len(req.Wifi))
+	bssids := make([]string, 0, len(req.Wifi))
+	for _, w := range req.Wifi {
+		if _, ok := scan[w.BSSID]; !ok {
+			bssids = append(bssids, w.BSSID)
+		}
+		scan[w.BSSID] = sourceRSSI(w.RSSI, w.EID)
+	}
+	if len(scan) < cfg.MinKnownAPs {
+		return nil, 1
+	}
+
+	fps, err := v.cache.FingerprintsWithAPs(ctx, bssids, cfg.MaxCandidates)
+	if err != nil || len(fps) == 0 {
+		return nil, 1
+	}
+
+	known := make(map[string]bool)
+	scores := make([]fingerprintScore, len(fps))
+	for i := range fps {
+		for b := range scan {
+			if _, ok := fps[i].APs[b]; ok {
+				known[b] = true
+			}
+		}
+		scores[i] = scoreFingerprint(scan, &fps[i], cfg.RSSIScaleDB)
+	}
+	if len(known) < cfg.MinKnownAPs {
+		return nil, 1
+	}
+
+	// Known APs that no fingerprint holds together
+	best, lat, lon, ok := nearestFingerprints(scores, cfg.Neighbors)
+	if !ok {
+		return &model.CheckResult{
+			Check:     model.CheckWifiFingerprint,
+			Code:      model.CheckCodeNeverCoVisible,
+			Value:     float64(len(known)),
+			Threshold: float64(cfg.MinKnownAPs),
+			Message:   fmt.Sprintf("%d known access points in the scan were never seen together", len(known)),
+		}, 1 - cfg.Penalty
+	}
+
+	distance := HaversineDistance(req.Latitude, req.Longitude, lat, lon) * 1000
+	allowed := cfg.MaxDistanceMeters + math.Max(float64(req.Accuracy), 0)
+	if distance > allowed {
+		return &model.CheckResult{
+			Check:     model.CheckWifiFingerprint,
+			Code:      model.CheckCodeFingerprintMismatch,
+			Value:     distance,
+			Threshold: allowed,
+			Message:   fmt.Sprintf("Reported position is %.0f m from the location matching the WiFi scan", distance),
+			Subject:   best.fp.ID,
+		}, 1 - cfg.Penalty
+	}
+
+	return &model.CheckResult{
+		Check:   model.CheckWifiFingerprint,
+		Code:    model.CheckCodeFingerprintMatched,
+		Passed:  true,
+		Value:   best.similarity,
+		Subject: best.fp.ID,
+	}, 1
+}
+
+// nearestFingerprints returns the most similar fingerprint holding at least
+// two scan APs together, and the similarity-weighted location of the k most
+// similar such fingerprints. A small fingerprint sharing a single AP may
+// score higher than one holding several, so fingerprints are judged by the
+// APs they share, not by rank. ok is false if none holds two scan APs.
+func nearestFingerprints(scores []fingerprintScore, k int) (best fingerprintScore, lat, lon float64, ok bool) {
+	sorted := append([]fingerprintScore(nil), scores...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].similarity > sorted[j].similarity })
+
+	var sumW float64
+	used := 0
+	for _, s := range sorted {
+		if used >= k || s.similarity <= 0 {
+			break
+		}
+		if s.common < 2 {
+			continue
+		}
+		if used == 0 {
+			best = s
+		}
+		used++
+		sumW += s.similarity
+		lat += s.fp.Latitude * s.similarity
+		lon += s.fp.Longitude * s.similarity
+	}
+	if used == 0 {
+		return fingerprintScore{}, 0, 0, false
+	}
+	return best, lat / sumW, lon / sumW, true
+}
+
+// scoreFingerprint combines a frequency-weighted Jaccard index, where each
+// fingerprint AP counts by the share of scans it appeared in, with the RSSI
+// distance over the common APs.
+func scoreFingerprint(scan map[string]int32, fp *model.WifiFingerprint, rssiScale float64) fingerprintScore {
+	score := fingerprintScore{fp: fp}
+	if fp.Scans <= 0 {
+		return score
+	}
+
+	var inter, union, sq float64
+	var rssiPairs int
+	union = float64(len(scan))
+	for b, ap := range fp.APs {
+		p := math.Min(float64(ap.Seen)/float64(fp.Scans), 1)
+		rssi, ok := scan[b]
+		if !ok {
+			union += p
+			continue
+		}
+		score.common++
+		inter += p
+		if rssi != 0 && ap.RSSISamples > 0 {
+			d := float64(rssi) - ap.RSSI
+			sq += d * d
+			rssiPairs++
+		}
+	}
+	if union <= 0 {
+		return score
+	}
+
+	score.similarity = inter / union
+	if rssiPairs > 0 && rssiScale > 0 {
+		rms := math.Sqrt(sq / float64(rssiPairs))
+		score.similarity /= 1 + rms/rssiScale
+	}
+	return score
+}
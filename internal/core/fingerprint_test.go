@@ -0,0 +1,203 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestScoreFingerprint(t *testing.T) {
+	tests := []struct {
+		name       string
+		scan       map[string]int32
+		fp         model.WifiFingerprint
+		rssiScale  float64
+		similarity float64
+		common     int
+	}{
+		{
+			name: "no scans",
+			scan: map[string]int32{"a": -50},
+			fp:   model.WifiFingerprint{APs: map[string]model.FingerprintAP{"a": {Seen: 1}}},
+		},
+		{
+			name: "identical",
+			scan: map[string]int32{"a": -50, "b": -60},
+			fp: model.WifiFingerprint{Scans: 4, APs: map[string]model.FingerprintAP{
+				"a": {Seen: 4, RSSI: -50, RSSISamples: 4},
+				"b": {Seen: 4, RSSI: -60, RSSISamples: 4},
+			}},
+			rssiScale:  10,
+			similarity: 1,
+			common:     2,
+		},
+		{
+			name: "disjoint",
+			scan: map[string]int32{"a": -50},
+			fp: model.WifiFingerprint{Scans: 4, APs: map[string]model.FingerprintAP{
+				"b": {Seen: 4},
+			}},
+		},
+		{
+			name: "APs count by how often they were seen",
+			scan: map[string]int32{"a": 0, "b": 0},
+			fp: model.WifiFingerprint{Scans: 10, APs: map[string]model.FingerprintAP{
+				"a": {Seen: 10},
+				"c": {Seen: 5},
+			}},
+			// 1 / (2 + 0.5)
+			similarity: 0.4,
+			common:     1,
+		},
+		{
+			name: "seen more often than scanned counts once",
+			scan: map[string]int32{"a": 0},
+			fp: model.WifiFingerprint{Scans: 2, APs: map[string]model.FingerprintAP{
+				"a": {Seen: 5},
+				"c": {Seen: 4},
+			}},
+			similarity: 0.5,
+			common:     1,
+		},
+		{
+			name: "RSSI distance lowers the similarity",
+			scan: map[string]int32{"a": -40, "b": -70},
+			fp: model.WifiFingerprint{Scans: 4, APs: map[string]model.FingerprintAP{
+				"a": {Seen: 4, RSSI: -50, RSSISamples: 4},
+				"b": {Seen: 4, RSSI: -60, RSSISamples: 4},
+			}},
+			rssiScale:  10,
+			similarity: 0.5,
+			common:     2,
+		},
+		{
+			name: "RSSI ignored without a scale",
+			scan: map[string]int32{"a": -40, "b": -70},
+			fp: model.WifiFingerprint{Scans: 4, APs: map[string]model.FingerprintAP{
+				"a": {Seen: 4, RSSI: -50, RSSISamples: 4},
+				"b": {Seen: 4, RSSI: -60, RSSISamples: 4},
+			}},
+			similarity: 1,
+			common:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreFingerprint(tt.scan, &tt.fp, tt.rssiScale)
+			if got.fp != &tt.fp {
+				t.Error("score doesn't point at the fingerprint")
+			}
+			if math.Abs(got.similarity-tt.similarity) > 1e-9 {
+				t.Errorf("similarity = %v, want %v", got.similarity, tt.similarity)
+			}
+			if got.common != tt.common {
+				t.Errorf("common = %d, want %d", got.common, tt.common)
+			}
+		})
+	}
+}
+
+func TestNearestFingerprints(t *testing.T) {
+	// A single-AP fingerprint sharing one scan AP outscores a large one
+	// holding both, which must still be the match.
+	scan := map[string]int32{"a": 0, "b": 0}
+	small := &model.WifiFingerprint{ID: "small", Latitude: 1, Longitude: 1, Scans: 1, APs: map[string]model.FingerprintAP{
+		"a": {Seen: 1},
+	}}
+	large := &model.WifiFingerprint{ID: "large", Latitude: 2, Longitude: 2, Scans: 1, APs: map[string]model.FingerprintAP{
+		"a": {Seen: 1}, "b": {Seen: 1}, "c": {Seen: 1}, "d": {Seen: 1}, "e": {Seen: 1},
+	}}
+	smallScore := scoreFingerprint(scan, small, 0)
+	largeScore := scoreFingerprint(scan, large, 0)
+	if smallScore.similarity <= largeScore.similarity {
+		t.Fatalf("small similarity %v doesn't outrank large %v", smallScore.similarity, largeScore.similarity)
+	}
+
+	fp := func(id string, lat float64) *model.WifiFingerprint {
+		return &model.WifiFingerprint{ID: id, Latitude: lat, Longitude: lat}
+	}
+
+	tests := []struct {
+		name   string
+		scores []fingerprintScore
+		k      int
+		ok     bool
+		best   string
+		lat    float64
+	}{
+		{
+			name:   "outranking single-AP fingerprint is skipped",
+			scores: []fingerprintScore{smallScore, largeScore},
+			k:      3,
+			ok:     true,
+			best:   "large",
+			lat:    2,
+		},
+		{
+			name: "skipped candidates don't use up k",
+			scores: []fingerprintScore{
+				{fp: fp("x", 9), similarity: 0.9, common: 1},
+				{fp: fp("y", 9), similarity: 0.8, common: 1},
+				{fp: fp("p", 1), similarity: 0.6, common: 2},
+				{fp: fp("q", 4), similarity: 0.3, common: 3},
+			},
+			k:    2,
+			ok:   true,
+			best: "p",
+			// (1*0.6 + 4*0.3) / 0.9
+			lat: 2,
+		},
+		{
+			name: "only the k most similar are averaged",
+			scores: []fingerprintScore{
+				{fp: fp("q", 4), similarity: 0.3, common: 2},
+				{fp: fp("p", 1), similarity: 0.6, common: 2},
+				{fp: fp("r", 9), similarity: 0.1, common: 2},
+			},
+			k:    2,
+			ok:   true,
+			best: "p",
+			lat:  2,
+		},
+		{
+			name: "zero similarity ends the search",
+			scores: []fingerprintScore{
+				{fp: fp("x", 9), similarity: 0.5, common: 1},
+				{fp: fp("z", 1), similarity: 0, common: 2},
+			},
+			k: 3,
+		},
+		{
+			name: "no fingerprint holds two scan APs",
+			scores: []fingerprintScore{
+				{fp: fp("x", 1), similarity: 0.9, common: 1},
+				{fp: fp("y", 2), similarity: 0.5, common: 1},
+			},
+			k: 3,
+		},
+		{
+			name: "no candidates",
+			k:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best, lat, lon, ok := nearestFingerprints(tt.scores, tt.k)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if best.fp.ID != tt.best {
+				t.Errorf("best = %s, want %s", best.fp.ID, tt.best)
+			}
+			if math.Abs(lat-tt.lat) > 1e-9 || math.Abs(lon-tt.lat) > 1e-9 {
+				t.Errorf("location = %v,%v, want %v,%v", lat, lon, tt.lat, tt.lat)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Reporting Gaps
+// ============================================
+//
+// After a gap (tunnel, parking garage, sleep mode) the device may have
+// stopped anywhere along its way or kept going, so it is expected to
+// reappear near the segment from its last fix to the position extrapolated
+// from its last velocity. The region widens with the length of the gap at a
+// share of the speed limit, so it only narrows what the speed check allows.
+// Reappearing outside it lowers confidence but doesn't fail the fix.
+
+// isGap reports whether the silence before req is long enough to treat the
+// fix as a reappearance.
+func (v *ValidationCore) isGap(gap time.Duration) bool {
+	return v.cfg.Gap.MinGap > 0 && gap >= v.cfg.Gap.MinGap
+}
+
+// speedLimitForGap returns the speed limit for a jump across a gap. Over long
+// gaps the device can't sustain its peak speed, so the limit is tightened.
+func (v *ValidationCore) speedLimitForGap(profile *model.DeviceProfile, gap time.Duration) float64 {
+	if v.cfg.Gap.LongGap > 0 && gap >= v.cfg.Gap.LongGap {
+		return profile.MaxSpeedKmH * v.cfg.Gap.SustainedSpeedFactor
+	}
+	return profile.MaxSpeedKmH
+}
+
+// checkReappearance compares a fix after a gap with the region predicted
+// from the last known velocity. window is newest first; limit is the speed
+// limit for the gap in km/h.
+func (v *ValidationCore) checkReappearance(window []model.TrackPoint, req *model.CoordinateRequest, gap time.Duration, limit float64) *speedCheckResult {
+	last := window[0]
+
+	// Last velocity (m/s) from the two latest fixes
+	var velEast, velNorth float64
+	if len(window) > 1 {
+		prev := window[1]
+		if dt := float64(last.Timestamp - prev.Timestamp); dt > 0 {
+			east, north := localOffset(prev.Latitude, prev.Longitude, last.Latitude, last.Longitude)
+			velEast, velNorth = east/dt, north/dt
+		}
+	}
+
+	horizon := math.Min(gap.Seconds(), v.cfg.Gap.PredictionHorizon.Seconds())
+	predLat, predLon := offsetLatLon(last.Latitude, last.Longitude, velEast*horizon, velNorth*horizon)
+
+	radius := math.Max(float64(last.Accuracy), 0) + math.Max(float64(req.Accuracy), 0) +
+		v.cfg.Gap.SpreadFactor*limit/3.6*gap.Seconds()
+	distance := distanceToPath([]model.GeoPoint{
+		{Latitude: last.Latitude, Longitude: last.Longitude},
+		{Latitude: predLat, Longitude: predLon},
+	}, req.Latitude, req.Longitude)
+
+	if distance > radius {
+		return &speedCheckResult{
+			valid: false,
+			reason: fmt.Sprintf(
+				"Reappeared %.0f m outside the region predicted after a %s gap (radius %.0f m)",
+				distance, gap, radius,
+			),
+			check:     model.CheckGap,
+			code:      model.CheckCodeOutsidePredicted,
+			value:     distance,
+			threshold: radius,
+		}
+	}
+	return &speedCheckResult{
+		valid:     true,
+		check:     model.CheckGap,
+		value:     distance,
+		threshold: radius,
+	}
+}
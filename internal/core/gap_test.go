@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestCheckReappearance(t *testing.T) {
+	v, _ := newTestCore(t)
+	ctx := context.Background()
+	profile := v.profiles.Default()
+	t0 := time.Now().Unix() - 3*3600
+	gap := int64(600)
+
+	// 36 km/h due east, one fix a minute
+	moving := func(id string) []model.CoordinateRequest {
+		return []model.CoordinateRequest{fixAt(id, 0, 0, t0), fixAt(id, 600, 0, t0+60), fixAt(id, 1200, 0, t0+120)}
+	}
+	parked := func(id string) []model.CoordinateRequest {
+		return []model.CoordinateRequest{fixAt(id, 0, 0, t0), fixAt(id, 0, 0, t0+60), fixAt(id, 0, 0, t0+120)}
+	}
+
+	tests := []struct {
+		name        string
+		track       func(id string) []model.CoordinateRequest
+		east, north float64 // where the device reappears after the gap
+		inRegion    bool
+	}{
+		{"straight line", moving, 7200, 0, true},
+		{"stopped then departing at 60 km/h", parked, 10000, 0, true},
+		{"turned north", moving, 1200, 8000, true},
+		{"doubled back at 100 km/h", moving, -15500, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seedTrack(t, v, tt.track(tt.name)...)
+			req := fixAt(tt.name, tt.east, tt.north, t0+120+gap)
+
+			res, err := v.validateSpeed(ctx, &req, profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.valid {
+				t.Fatalf("speed check failed: %s", res.reason)
+			}
+			if res.gap == nil {
+				t.Fatal("no reappearance check after the gap")
+			}
+			if res.gap.valid != tt.inRegion {
+				t.Errorf("in region = %v (%.0f m, radius %.0f m), want %v", res.gap.valid, res.gap.value, res.gap.threshold, tt.inRegion)
+			}
+
+			// Outside the region only lowers confidence
+			out, err := (&speedRule{v}).Evaluate(ctx, &RuleContext{Request: &req, Profile: profile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.Failed {
+				t.Errorf("speed rule failed: %s", out.Reason)
+			}
+			if (out.Penalty > 0) == tt.inRegion {
+				t.Errorf("penalty = %v", out.Penalty)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"coordinate-validator/internal/cache"
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Geofence Registry
+// ============================================
+
+// GeofenceRegistry holds forbidden and expected zones. Zones come from
+// GeoJSON files loaded at startup and from Redis, where they are managed
+// through the admin API; a Redis zone replaces a file zone with the same ID.
+// Lookups use an in-memory grid index refreshed every RefreshInterval.
+type GeofenceRegistry struct {
+	cache  *cache.RedisCache
+	cfg    *config.GeofenceConfig
+	static []model.Geofence
+	index  *snapshot[*geoIndex]
+}
+
+func NewGeofenceRegistry(cache *cache.RedisCache, cfg *config.GeofenceConfig) *GeofenceRegistry {
+	r := &GeofenceRegistry{
+		cache: cache,
+		cfg:   cfg,
+	}
+	for _, path := range cfg.Files {
+		zones, err := LoadGeofenceFile(path)
+		if err != nil {
+			log.Printf("Warning: failed to load geofences: %v", err)
+			continue
+		}
+		r.static = append(r.static, zones...)
+	}
+	r.index = newSnapshot("geofences", cfg.RefreshInterval, r.loadIndex)
+	return r
+}
+
+// loadIndex indexes the current zones; without Redis only file zones.
+func (r *GeofenceRegistry) loadIndex(ctx context.Context) (*geoIndex, error) {
+	zones, err := r.List(ctx)
+	if err != nil {
+		return newGeoIndex(r.static, r.cfg.IndexCellDegrees), err
+	}
+	return newGeoIndex(zones, r.cfg.IndexCellDegrees), nil
+}
+
+// Evaluate returns a check per violated zone, or a single passed check, and
+// the confidence multiplier. Only zones containing the point and the
+// device's expected zones are inspected.
+func (r *GeofenceRegistry) Evaluate(ctx context.Context, deviceID string, lat, lon float64) ([]model.CheckResult, float64) {
+	idx := r.index.get(ctx)
+	if len(idx.zones) == 0 {
+		return nil, 1
+	}
+
+	inside := make(map[int]bool)
+	var checks []model.CheckResult
+	multiplier := 1.0
+
+	for _, i := range idx.containing(lat, lon) {
+		inside[i] = true
+		z := &idx.zones[i].zone
+		if z.Kind != model.GeofenceForbidden || !appliesTo(z, deviceID) {
+			continue
+		}
+		multiplier *= 1 - z.Penalty
+		checks = append(checks, model.CheckResult{
+			Check:     model.CheckGeofence,
+			Code:      model.CheckCodeForbiddenZone,
+			Threshold: z.Penalty,
+			Message:   fmt.Sprintf("Position is inside forbidden zone %q", zoneLabel(z)),
+			Subject:   z.ID,
+		})
+	}
+
+	// Expected zones are alternatives: inside any one of them is enough
+	var expected []*model.Geofence
+	insideExpected := false
+	for _, i := range idx.expected {
+		z := &idx.zones[i].zone
+		if !appliesTo(z, deviceID) {
+			continue
+		}
+		expected = append(expected, z)
+		insideExpected = insideExpected || inside[i]
+	}
+	if len(expected) > 0 {
+		if !insideExpected {
+			ids := make([]string, len(expected))
+			labels := make([]string, len(expected))
+			penalty := 0.0
+			for i, z := range expected {
+				ids[i] = z.ID
+				labels[i] = fmt.Sprintf("%q", zoneLabel(z))
+				penalty = math.Max(penalty, z.Penalty)
+			}
+			multiplier *= 1 - penalty
+			checks = append(checks, model.CheckResult{
+				Check:     model.CheckGeofence,
+				Code:      model.CheckCodeOutsideExpectedZone,
+				Threshold: penalty,
+				Message:   fmt.Sprintf("Position is outside expected zone %s", strings.Join(labels, ", ")),
+				Subject:   strings.Join(ids, ","),
+			})
+		}
+	}
+
+	if len(checks) == 0 {
+		checks = append(checks, model.CheckResult{
+			Check:  model.CheckGeofence,
+			Code:   model.CheckCodeOK,
+			Passed: true,
+		})
+	}
+	return checks, multiplier
+}
+
+func appliesTo(z *model.Geofence, deviceID string) bool {
+	if len(z.DevicePrefixes) == 0 {
+		return true
+	}
+	for _, p := range z.DevicePrefixes {
+		if strings.HasPrefix(deviceID, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func zoneLabel(z *model.Geofence) string {
+	if z.Name != "" {
+		return z.Name
+	}
+	return z.ID
+}
+
+// ============================================
+// Management
+// ============================================
+
+// List returns file zones merged with Redis zones.
+func (r *GeofenceRegistry) List(ctx context.Context) ([]model.Geofence, error) {
+	stored, err := r.cache.ListGeofences(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool, len(stored))
+	for _, z := range stored {
+		ids[z.ID] = true
+	}
+	zones := stored
+	for _, z := range r.static {
+		if !ids[z.ID] {
+			zones = append(zones, z)
+		}
+	}
+	return zones, nil
+}
+
+func (r *GeofenceRegistry) Set(ctx context.Context, zone *model.Geofence) error {
+	z := withGeofenceDefaults(*zone)
+	if err := validateGeofence(&z); err != nil {
+		return err
+	}
+	if err := r.cache.SetGeofence(ctx, &z); err != nil {
+		return err
+	}
+	r.index.invalidate()
+	return nil
+}
+
+// Delete removes a Redis zone. File zones can only be removed from their file.
+func (r *GeofenceRegistry) Delete(ctx context.Context, id string) error {
+	if err := r.cache.DeleteGeofence(ctx, id); err != nil {
+		return err
+	}
+	r.index.invalidate()
+	return nil
+}
+
+// ============================================
+// Spatial Index
+// ============================================
+
+// Zones whose bounding box covers more grid cells than this are checked on
+// every lookup instead of being gridded
+const maxIndexCells = 4096
+
+type indexedZone struct {
+	zone                           model.Geofence
+	minLat, minLon, maxLat, maxLon float64
+}
+
+// geoIndex is a uniform lat/lon grid over zone bounding boxes.
+type geoIndex struct {
+	cellDeg  float64
+	zones    []indexedZone
+	cells    map[[2]int][]int
+	wide     []int
+	expected []int
+}
+
+func newGeoIndex(zones []model.Geofence, cellDeg float64) *geoIndex {
+	if cellDeg <= 0 {
+		cellDeg = 0.5
+	}
+	g := &geoIndex{
+		cellDeg: cellDeg,
+		cells:   make(map[[2]int][]int),
+	}
+
+	for _, z := range zones {
+		iz := indexedZone{zone: z, minLat: 90, minLon: 180, maxLat: -90, maxLon: -180}
+		for _, p := range z.Polygons {
+			if len(p) == 0 {
+				continue
+			}
+			for _, pt := range p[0] {
+				iz.minLon = math.Min(iz.minLon, pt[0])
+				iz.maxLon = math.Max(iz.maxLon, pt[0])
+				iz.minLat = math.Min(iz.minLat, pt[1])
+				iz.maxLat = math.Max(iz.maxLat, pt[1])
+			}
+		}
+		if iz.minLat > iz.maxLat {
+			continue
+		}
+
+		i := len(g.zones)
+		g.zones = append(g.zones, iz)
+		if z.Kind == model.GeofenceExpected {
+			g.expected = append(g.expected, i)
+		}
+
+		r0, c0 := g.cell(iz.minLat, iz.minLon)
+		r1, c1 := g.cell(iz.maxLat, iz.maxLon)
+		if (r1-r0+1)*(c1-c0+1) > maxIndexCells {
+			g.wide = append(g.wide, i)
+			continue
+		}
+		for row := r0; row <= r1; row++ {
+			for col := c0; col <= c1; col++ {
+				key := [2]int{row, col}
+				g.cells[key] = append(g.cells[key], i)
+			}
+		}
+	}
+	return g
+}
+
+func (g *geoIndex) cell(lat, lon float64) (int, int) {
+	return int(math.Floor(lat / g.cellDeg)), int(math.Floor(lon / g.cellDeg))
+}
+
+// containing returns the indexes of zones containing the point.
+func (g *geoIndex) containing(lat, lon float64) []int {
+	row, col := g.cell(lat, lon)
+
+	var out []int
+	for _, candidates := range [][]int{g.cells[[2]int{row, col}], g.wide} {
+		for _, i := range candidates {
+			if g.zones[i].contains(lat, lon) {
+				out = append(out, i)
+			}
+		}
+	}
+	return out
+}
+
+func (iz *indexedZone) contains(lat, lon float64) bool {
+	if lat < iz.minLat || lat > iz.maxLat || lon < iz.minLon || lon > iz.maxLon {
+		return false
+	}
+	for _, p := range iz.zone.Polygons {
+		if polygonContains(p, lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+// polygonContains reports whether the point is inside the outer ring and
+// outside every hole.
+func polygonContains(p model.Polygon, lat, lon float64) bool {
+	if len(p) == 0 || !ringContains(p[0], lat, lon) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if ringContains(hole, lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains is the even-odd ray casting test on [lon, lat] positions.
+func ringContains(ring [][2]float64, lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"coordinate-validator/internal/model"
+)
+
+// square returns a closed [lon, lat] ring around a point.
+func square(lat, lon, half float64) [][2]float64 {
+	return [][2]float64{
+		{lon - half, lat - half},
+		{lon + half, lat - half},
+		{lon + half, lat + half},
+		{lon - half, lat + half},
+		{lon - half, lat - half},
+	}
+}
+
+func TestGeoIndexContaining(t *testing.T) {
+	zones := []model.Geofence{
+		// 0: a 2x2 degree block with a 1x1 degree hole in the middle
+		{ID: "ring", Kind: model.GeofenceForbidden, Polygons: []model.Polygon{
+			{square(10, 10, 1), square(10, 10, 0.5)},
+		}},
+		// 1: overlaps the east edge of the block
+		{ID: "east", Kind: model.GeofenceForbidden, Polygons: []model.Polygon{
+			{square(10, 11, 0.4)},
+		}},
+		// 2: two disjoint parts
+		{ID: "multi", Kind: model.GeofenceExpected, Polygons: []model.Polygon{
+			{square(-20, -40, 0.2)},
+			{square(-20, -30, 0.2)},
+		}},
+		// 3: covers more cells than are indexed
+		{ID: "wide", Kind: model.GeofenceForbidden, Polygons: []model.Polygon{
+			{square(0, 100, 30)},
+		}},
+		// skipped: no rings
+		{ID: "empty", Kind: model.GeofenceForbidden},
+	}
+	g := newGeoIndex(zones, 0.5)
+
+	if len(g.zones) != 4 {
+		t.Fatalf("indexed %d zones, want 4", len(g.zones))
+	}
+	if !reflect.DeepEqual(g.expected, []int{2}) {
+		t.Errorf("expected zones = %v, want [2]", g.expected)
+	}
+	if !reflect.DeepEqual(g.wide, []int{3}) {
+		t.Errorf("wide zones = %v, want [3]", g.wide)
+	}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     []int
+	}{
+		{"inside the ring", 10, 9.2, []int{0}},
+		{"inside the hole", 10, 10, nil},
+		{"overlap of two zones", 10, 10.8, []int{0, 1}},
+		{"only the overlapping zone", 10, 11.3, []int{1}},
+		{"empty cell", 20, 20, nil},
+		{"first part of a multipolygon", -20, -40, []int{2}},
+		{"second part of a multipolygon", -20, -30.1, []int{2}},
+		{"inside the bounding box, between the parts", -20, -35, nil},
+		{"wide zone", 15, 110, []int{3}},
+		{"far from every zone", -60, 150, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.containing(tt.lat, tt.lon); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("containing(%v, %v) = %v, want %v", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeofenceRegistryChanges(t *testing.T) {
+	v, _ := newTestCore(t)
+	r := v.geofences
+	ctx := context.Background()
+
+	// Indexed before the zone exists; a managed zone without a penalty
+	// gets the same default as a file zone
+	if checks, m := r.Evaluate(ctx, "van-1", 10, 10); checks != nil || m != 1 {
+		t.Fatalf("no zones: checks = %v, multiplier = %v", checks, m)
+	}
+
+	zone := model.Geofence{ID: "sea", Kind: model.GeofenceForbidden, Polygons: []model.Polygon{{square(10, 10, 1)}}}
+	if err := r.Set(ctx, &zone); err != nil {
+		t.Fatal(err)
+	}
+	checks, m := r.Evaluate(ctx, "van-1", 10, 10)
+	if len(checks) != 1 || checks[0].Code != model.CheckCodeForbiddenZone || m != 0 {
+		t.Errorf("after Set: checks = %v, multiplier = %v, want the default penalty at once", checks, m)
+	}
+
+	if err := r.Delete(ctx, "sea"); err != nil {
+		t.Fatal(err)
+	}
+	if checks, m := r.Evaluate(ctx, "van-1", 10, 10); checks != nil || m != 1 {
+		t.Errorf("after Delete: checks = %v, multiplier = %v", checks, m)
+	}
+}
+
+func TestParseGeofencesPenalty(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties string
+		want       float64
+	}{
+		{"unset", `{"id": "a", "kind": "forbidden"}`, 1},
+		{"zero", `{"id": "a", "kind": "forbidden", "penalty": 0}`, 1},
+		{"set", `{"id": "a", "kind": "forbidden", "penalty": 0.4}`, 0.4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := `{"type": "Feature", "properties": ` + tt.properties + `,
+				"geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}`
+			zones, err := ParseGeofences([]byte(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if zones[0].Penalty != tt.want {
+				t.Errorf("penalty = %v, want %v", zones[0].Penalty, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// GeoJSON Zones
+// ============================================
+
+// geoJSON covers the subset of GeoJSON used for zones: FeatureCollection,
+// Feature and Polygon/MultiPolygon geometries.
+type geoJSON struct {
+	Type        string              `json:"type"`
+	ID          interface{}         `json:"id,omitempty"`
+	Features    []geoJSON           `json:"features,omitempty"`
+	Geometry    *geoJSON            `json:"geometry,omitempty"`
+	Properties  *geofenceProperties `json:"properties,omitempty"`
+	Coordinates json.RawMessage     `json:"coordinates,omitempty"`
+}
+
+// geofenceProperties are the Feature properties describing a zone.
+type geofenceProperties struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Kind           string   `json:"kind"` // "forbidden" or "expected"
+	Penalty        float64  `json:"penalty"`
+	DevicePrefixes []string `json:"device_prefixes"`
+}
+
+// LoadGeofenceFile reads zones from a GeoJSON file.
+func LoadGeofenceFile(path string) ([]model.Geofence, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	zones, err := ParseGeofences(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return zones, nil
+}
+
+// ParseGeofences builds zones from a FeatureCollection or a single Feature.
+func ParseGeofences(data []byte) ([]model.Geofence, error) {
+	var doc geoJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	features := doc.Features
+	switch doc.Type {
+	case "FeatureCollection":
+	case "Feature":
+		features = []geoJSON{doc}
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON type %q", doc.Type)
+	}
+
+	zones := make([]model.Geofence, 0, len(features))
+	for i, f := range features {
+		if f.Geometry == nil {
+			return nil, fmt.Errorf("feature %d has no geometry", i)
+		}
+		polygons, err := parseGeometry(f.Geometry)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+
+		zone := model.Geofence{Polygons: polygons}
+		if p := f.Properties; p != nil {
+			zone.ID = p.ID
+			zone.Name = p.Name
+			zone.Kind = model.GeofenceKind(strings.ToUpper(p.Kind))
+			zone.DevicePrefixes = p.DevicePrefixes
+			zone.Penalty = p.Penalty
+		}
+		if zone.ID == "" && f.ID != nil {
+			zone.ID = fmt.Sprint(f.ID)
+		}
+		zone = withGeofenceDefaults(zone)
+		if err := validateGeofence(&zone); err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// countryFeature is a Feature of a country boundary dataset.
+type countryFeature struct {
+	Geometry   *geoJSON               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Property names holding the ISO 3166-1 alpha-2 code in common datasets
+// (Natural Earth, datasets/geo-countries), in order of preference
+var countryCodeProperties = []string{"iso_a2", "ISO_A2", "iso_a2_eh", "ISO_A2_EH", "ISO3166-1-Alpha-2", "code"}
+
+// LoadCountryFile reads country boundaries from a GeoJSON FeatureCollection.
+// Each zone's ID is the country's ISO 3166-1 alpha-2 code; features without
+// one are skipped.
+func LoadCountryFile(path string) ([]model.Geofence, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Features []countryFeature `json:"features"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var countries []model.Geofence
+	for i, f := range doc.Features {
+		code := countryCode(f.Properties)
+		if code == "" || f.Geometry == nil {
+			continue
+		}
+		polygons, err := parseGeometry(f.Geometry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: feature %d: %w", path, i, err)
+		}
+		name, _ := f.Properties["name"].(string)
+		countries = append(countries, model.Geofence{ID: code, Name: name, Polygons: polygons})
+	}
+	return countries, nil
+}
+
+func countryCode(props map[string]interface{}) string {
+	for _, key := range countryCodeProperties {
+		// Natural Earth uses -99 for territories without a code
+		if code, ok := props[key].(string); ok && len(code) == 2 {
+			return strings.ToUpper(code)
+		}
+	}
+	return ""
+}
+
+// ParseGeometry parses a Polygon or MultiPolygon geometry, or a Feature
+// carrying one.
+func ParseGeometry(data []byte) ([]model.Polygon, error) {
+	var g geoJSON
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	if g.Type == "Feature" {
+		if g.Geometry == nil {
+			return nil, fmt.Errorf("feature has no geometry")
+		}
+		return parseGeometry(g.Geometry)
+	}
+	return parseGeometry(&g)
+}
+
+// EncodeGeometry returns polygons as a GeoJSON MultiPolygon geometry.
+func EncodeGeometry(polygons []model.Polygon) ([]byte, error) {
+	return json.Marshal(struct {
+		Type        string          `json:"type"`
+		Coordinates []model.Polygon `json:"coordinates"`
+	}{"MultiPolygon", polygons})
+}
+
+func parseGeometry(g *geoJSON) ([]model.Polygon, error) {
+	switch g.Type {
+	case "Polygon":
+		var p model.Polygon
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, err
+		}
+		return []model.Polygon{p}, nil
+	case "MultiPolygon":
+		var mp []model.Polygon
+		if err := json.Unmarshal(g.Coordinates, &mp); err != nil {
+			return nil, err
+		}
+		return mp, nil
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+}
+
+// Penalty of a zone that doesn't set one: it always yields INVALID
+const defaultGeofencePenalty = 1.0
+
+// withGeofenceDefaults fills what a zone from a file or the admin API may
+// leave unset.
+func withGeofenceDefaults(z model.Geofence) model.Geofence {
+	if z.Penalty == 0 {
+		z.Penalty = defaultGeofencePenalty
+	}
+	return z
+}
+
+// validateGeofence checks a zone before it is stored or indexed.
+func validateGeofence(z *model.Geofence) error {
+	if z.ID == "" {
+		return fmt.Errorf("zone id is required")
+	}
+	if z.Kind != model.GeofenceForbidden && z.Kind != model.GeofenceExpected {
+		return fmt.Errorf("zone %q: kind must be FORBIDDEN or EXPECTED", z.ID)
+	}
+	if z.Penalty < 0 || z.Penalty > 1 {
+		return fmt.Errorf("zone %q: penalty must be within [0, 1]", z.ID)
+	}
+	if len(z.Polygons) == 0 {
+		return fmt.Errorf("zone %q: no polygons", z.ID)
+	}
+	for _, p := range z.Polygons {
+		if len(p) == 0 || len(p[0]) < 4 {
+			return fmt.Errorf("zone %q: polygon ring needs at least 4 positions", z.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// GNSS Metadata
+// ============================================
+
+// checkGNSS scores the receiver metadata reported with a fix: mock providers
+// and fixes without a fix, accuracy the satellite geometry can't support,
+// and reported speed and course against the displacement from the previous
+// fix. It returns the checks and the confidence multiplier.
+func (v *ValidationCore) checkGNSS(ctx context.Context, req *model.CoordinateRequest) ([]model.CheckResult, float64) {
+	g := req.GNSS
+	if g == nil {
+		return nil, 1
+	}
+	cfg := v.cfg.GNSS
+	acc := float64(req.Accuracy)
+
+	var checks []model.CheckResult
+	multiplier := 1.0
+
+	// Provider and fix flags, then accuracy vs satellite geometry
+	quality := model.CheckResult{
+		Check:     model.CheckGNSSQuality,
+		Code:      model.CheckCodeOK,
+		Passed:    true,
+		Value:     acc,
+		Threshold: cfg.SuspiciousAccuracyMeters,
+	}
+	switch {
+	case g.Provider == model.GNSSProviderMock:
+		quality.Code = model.CheckCodeMockProvider
+		quality.Message = "Position comes from a mock location provider"
+	case g.FixType == model.FixTypeNone:
+		quality.Code = model.CheckCodeNoFix
+		quality.Message = "Receiver reports no fix"
+	case acc < cfg.SuspiciousAccuracyMeters && g.Satellites > 0 && g.Satellites < cfg.MinSatellites:
+		quality.Code = model.CheckCodeImplausibleAccuracy
+		quality.Message = fmt.Sprintf("Accuracy %.1f m reported with only %d satellites", acc, g.Satellites)
+	case acc < cfg.SuspiciousAccuracyMeters && g.HDOP > cfg.MaxHDOP:
+		quality.Code = model.CheckCodeImplausibleAccuracy
+		quality.Message = fmt.Sprintf("Accuracy %.1f m reported with HDOP %.1f", acc, g.HDOP)
+	}
+	if quality.Code != model.CheckCodeOK {
+		quality.Passed = false
+		multiplier *= 1 - cfg.SpoofingPenalty
+	}
+	checks = append(checks, quality)
+
+	if g.SpeedKmH == nil && g.Course == nil {
+		return checks, multiplier
+	}
+
+	// Compare with the displacement from the previous fix; without a recent
+	// one the reported values can't be checked
+	prev, err := v.cache.GetTrackBefore(ctx, req.DeviceID, req.Timestamp, 1)
+	if err != nil || len(prev) == 0 {
+		return checks, multiplier
+	}
+	p := prev[0]
+	dt := float64(req.Timestamp - p.Timestamp)
+	if dt <= 0 || time.Duration(dt)*time.Second > cfg.MaxCompareInterval {
+		return checks, multiplier
+	}
+
+	distance := HaversineDistance(p.Latitude, p.Longitude, req.Latitude, req.Longitude) * 1000
+	noise := math.Max(float64(p.Accuracy), 0) + math.Max(acc, 0)
+	computed := distance / dt * 3.6
+
+	if g.SpeedKmH != nil {
+		diff := math.Abs(*g.SpeedKmH - computed)
+		c := model.CheckResult{
+			Check:     model.CheckGNSSSpeed,
+			Code:      model.CheckCodeOK,
+			Passed:    true,
+			Value:     diff,
+			Threshold: cfg.SpeedToleranceKmH + noise/dt*3.6,
+		}
+		if diff > c.Threshold {
+			c.Code = model.CheckCodeSpeedMismatch
+			c.Passed = false
+			c.Message = fmt.Sprintf(
+				"Reported speed %.1f km/h differs from computed %.1f km/h",
+				*g.SpeedKmH, computed,
+			)
+			multiplier *= 1 - cfg.MismatchPenalty
+		}
+		checks = append(checks, c)
+	}
+
+	// Course is only meaningful while moving and beyond the fixes' noise
+	minSpeed := v.cfg.Trajectory.MinHeadingSpeedKmH
+	moving := computed >= minSpeed && (g.SpeedKmH == nil || *g.SpeedKmH >= minSpeed)
+	if g.Course != nil && moving && distance > noise {
+		bearing := Bearing(p.Latitude, p.Longitude, req.Latitude, req.Longitude)
+		diff := headingDiff(*g.Course, bearing)
+		c := model.CheckResult{
+			Check:     model.CheckGNSSCourse,
+			Code:      model.CheckCodeOK,
+			Passed:    true,
+			Value:     diff,
+			Threshold: cfg.CourseToleranceDeg,
+		}
+		if diff > cfg.CourseToleranceDeg {
+			c.Code = model.CheckCodeCourseMismatch
+			c.Passed = false
+			c.Message = fmt.Sprintf(
+				"Reported course %.0f° differs from displacement bearing %.0f°",
+				*g.Course, bearing,
+			)
+			multiplier *= 1 - cfg.MismatchPenalty
+		}
+		checks = append(checks, c)
+	}
+
+	return checks, multiplier
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Track Smoothing: Constant-Velocity Kalman Filter
+// ============================================
+//
+// The filter runs independently on the east and north axes of a local
+// tangent plane. Each axis has state [position, velocity] and a 2x2
+// covariance stored as [p00, p01, p11].
+
+const (
+	earthRadiusMeters = 6371000.0
+
+	// Minimum measurement noise (meters) for fixes reporting tiny accuracy
+	minMeasurementNoise = 5.0
+
+	// Initial velocity variance ((m/s)^2) when the filter is (re)started
+	initialVelocityVariance = 100.0
+)
+
+type kalmanResult struct {
+	state     *model.KalmanState
+	latitude  float64 // corrected
+	longitude float64 // corrected
+	nis       float64 // normalized innovation squared
+}
+
+// anomalyScore maps the normalized innovation squared to [0, 1) using the
+// chi-square CDF with two degrees of freedom.
+func (r kalmanResult) anomalyScore() float64 {
+	return 1 - math.Exp(-r.nis/2)
+}
+
+// kalmanStep runs one predict/update cycle of the filter for req. A nil
+// previous state, a backwards step or a gap longer than the reset gap
+// restarts the filter at the measurement.
+func (v *ValidationCore) kalmanStep(prev *model.KalmanState, req *model.CoordinateRequest) kalmanResult {
+	noise := math.Max(float64(req.Accuracy), minMeasurementNoise)
+	r := noise * noise
+
+	dt := 0.0
+	if prev != nil {
+		dt = float64(req.Timestamp - prev.Timestamp)
+	}
+	if prev == nil || dt <= 0 || time.Duration(dt)*time.Second > v.cfg.Kalman.ResetGap {
+		return kalmanResult{
+			state: &model.KalmanState{
+				Latitude:  req.Latitude,
+				Longitude: req.Longitude,
+				CovEast:   [3]float64{r, 0, initialVelocityVariance},
+				CovNorth:  [3]float64{r, 0, initialVelocityVariance},
+				Timestamp: req.Timestamp,
+			},
+			latitude:  req.Latitude,
+			longitude: req.Longitude,
+		}
+	}
+
+	// Predict
+	predLat, predLon := offsetLatLon(prev.Latitude, prev.Longitude, prev.VelEast*dt, prev.VelNorth*dt)
+	q := v.cfg.Kalman.AccelNoiseMS2 * v.cfg.Kalman.AccelNoiseMS2
+	covE := predictCov(prev.CovEast, dt, q)
+	covN := predictCov(prev.CovNorth, dt, q)
+
+	// Innovation in meters relative to the predicted position
+	yE, yN := localOffset(predLat, predLon, req.Latitude, req.Longitude)
+
+	// Update
+	dE, velE, covE, sE := updateAxis(covE, yE, r)
+	dN, velN, covN, sN := updateAxis(covN, yN, r)
+
+	lat, lon := offsetLatLon(predLat, predLon, dE, dN)
+
+	return kalmanResult{
+		state: &model.KalmanState{
+			Latitude:  lat,
+			Longitude: lon,
+			VelEast:   prev.VelEast + velE,
+			VelNorth:  prev.VelNorth + velN,
+			CovEast:   covE,
+			CovNorth:  covN,
+			Timestamp: req.Timestamp,
+		},
+		latitude:  lat,
+		longitude: lon,
+		nis:       yE*yE/sE + yN*yN/sN,
+	}
+}
+
+// predictCov propagates one axis covariance through the constant-velocity
+// model with white acceleration noise of variance q.
+func predictCov(p [3]float64, dt, q float64) [3]float64 {
+	dt2 := dt * dt
+	return [3]float64{
+		p[0] + 2*dt*p[1] + dt2*p[2] + q*dt2*dt2/4,
+		p[1] + dt*p[2] + q*dt2*dt/2,
+		p[2] + q*dt2,
+	}
+}
+
+// updateAxis applies a position measurement with innovation y and variance r.
+// It returns the position and velocity corrections, the posterior covariance
+// and the innovation variance.
+func updateAxis(p [3]float64, y, r float64) (float64, float64, [3]float64, float64) {
+	s := p[0] + r
+	k0 := p[0] / s
+	k1 := p[1] / s
+
+	post := [3]float64{
+		(1 - k0) * p[0],
+		(1 - k0) * p[1],
+		p[2] - k1*p[1],
+	}
+	return k0 * y, k1 * y, post, s
+}
+
+// localOffset returns the east/north offset in meters from point 1 to point 2.
+func localOffset(lat1, lon1, lat2, lon2 float64) (float64, float64) {
+	east := toRad(lon2-lon1) * math.Cos(toRad(lat1)) * earthRadiusMeters
+	north := toRad(lat2-lat1) * earthRadiusMeters
+	return east, north
+}
+
+// offsetLatLon moves a point by east/north meters.
+func offsetLatLon(lat, lon, east, north float64) (float64, float64) {
+	newLat := lat + north/earthRadiusMeters*180/math.Pi
+	newLon := lon + east/(earthRadiusMeters*math.Cos(toRad(lat)))*180/math.Pi
+	return newLat, newLon
+}
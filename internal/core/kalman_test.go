@@ -0,0 +1,126 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+func TestKalmanStep(t *testing.T) {
+	v := &ValidationCore{cfg: &config.ValidationConfig{
+		Kalman: config.KalmanConfig{AccelNoiseMS2: 3, InnovationGate: 9.21, ResetGap: 10 * time.Minute},
+	}}
+
+	const lat, lon, ts = 55.75, 37.62, int64(1700000000)
+	settled := func(velEast float64) *model.KalmanState {
+		return &model.KalmanState{
+			Latitude:  lat,
+			Longitude: lon,
+			VelEast:   velEast,
+			CovEast:   [3]float64{25, 0, 1},
+			CovNorth:  [3]float64{25, 0, 1},
+			Timestamp: ts,
+		}
+	}
+	// 100 m east of the start, where 10 m/s carries the track in 10 s
+	predLat, predLon := offsetLatLon(lat, lon, 100, 0)
+	farLat, farLon := offsetLatLon(lat, lon, 0, 1000)
+
+	tests := []struct {
+		name      string
+		prev      *model.KalmanState
+		req       model.CoordinateRequest
+		reset     bool
+		wantLat   float64
+		wantLon   float64
+		tolMeters float64
+		minNIS    float64
+		maxNIS    float64
+	}{
+		{
+			name:    "no previous state starts at the measurement",
+			req:     model.CoordinateRequest{Latitude: lat, Longitude: lon, Accuracy: 10, Timestamp: ts},
+			reset:   true,
+			wantLat: lat, wantLon: lon,
+		},
+		{
+			name:    "backwards step restarts",
+			prev:    settled(0),
+			req:     model.CoordinateRequest{Latitude: farLat, Longitude: farLon, Accuracy: 10, Timestamp: ts - 5},
+			reset:   true,
+			wantLat: farLat, wantLon: farLon,
+		},
+		{
+			name:    "gap longer than the reset gap restarts",
+			prev:    settled(0),
+			req:     model.CoordinateRequest{Latitude: farLat, Longitude: farLon, Accuracy: 10, Timestamp: ts + 3600},
+			reset:   true,
+			wantLat: farLat, wantLon: farLon,
+		},
+		{
+			name:      "stationary fix stays put",
+			prev:      settled(0),
+			req:       model.CoordinateRequest{Latitude: lat, Longitude: lon, Accuracy: 10, Timestamp: ts + 10},
+			wantLat:   lat,
+			wantLon:   lon,
+			tolMeters: 0.01,
+			maxNIS:    1e-6,
+		},
+		{
+			name:      "fix on the predicted track",
+			prev:      settled(10),
+			req:       model.CoordinateRequest{Latitude: predLat, Longitude: predLon, Accuracy: 10, Timestamp: ts + 10},
+			wantLat:   predLat,
+			wantLon:   predLon,
+			tolMeters: 0.01,
+			maxNIS:    1e-6,
+		},
+		{
+			name:      "jump off the track follows the fix but is gated",
+			prev:      settled(0),
+			req:       model.CoordinateRequest{Latitude: farLat, Longitude: farLon, Accuracy: 10, Timestamp: ts + 10},
+			wantLat:   farLat,
+			wantLon:   farLon,
+			tolMeters: 10,
+			minNIS:    9.21,
+			maxNIS:    math.Inf(1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := v.kalmanStep(tt.prev, &tt.req)
+
+			if got.state == nil {
+				t.Fatal("state is nil")
+			}
+			if got.state.Timestamp != tt.req.Timestamp {
+				t.Errorf("state timestamp = %d, want %d", got.state.Timestamp, tt.req.Timestamp)
+			}
+			if got.state.Latitude != got.latitude || got.state.Longitude != got.longitude {
+				t.Errorf("state position %v,%v differs from result %v,%v",
+					got.state.Latitude, got.state.Longitude, got.latitude, got.longitude)
+			}
+
+			if tt.reset {
+				if got.latitude != tt.req.Latitude || got.longitude != tt.req.Longitude {
+					t.Errorf("reset position = %v,%v, want the measurement", got.latitude, got.longitude)
+				}
+				if got.nis != 0 || got.state.VelEast != 0 || got.state.VelNorth != 0 {
+					t.Errorf("reset nis = %v, velocity = %v,%v, want zero", got.nis, got.state.VelEast, got.state.VelNorth)
+				}
+				return
+			}
+
+			if d := HaversineDistance(got.latitude, got.longitude, tt.wantLat, tt.wantLon) * 1000; d > tt.tolMeters {
+				t.Errorf("position is %.3f m from want, tolerance %.3f m", d, tt.tolMeters)
+			}
+			if got.nis < tt.minNIS || got.nis > tt.maxNIS {
+				t.Errorf("nis = %v, want within [%v, %v]", got.nis, tt.minNIS, tt.maxNIS)
+			}
+		})
+	}
+}
@@ -2,8 +2,8 @@ package core
 
 import (
 	"context"
-	"log"
 	"math"
+	"regexp"
 	"time"
 
 	"coordinate-validator/internal/cache"
@@ -12,14 +12,16 @@ import (
 )
 
 type LearningCore struct {
-	cache *cache.RedisCache
-	cfg   *config.ValidationConfig
+	cache        *cache.RedisCache
+	cfg          *config.ValidationConfig
+	ssidPatterns []*regexp.Regexp
 }
 
 func NewLearningCore(cache *cache.RedisCache, cfg *config.ValidationConfig) *LearningCore {
 	return &LearningCore{
-		cache: cache,
-		cfg:   cfg,
+		cache:        cache,
+		cfg:          cfg,
+		ssidPatterns: compileSSIDPatterns(cfg.Exclusion.SSIDPatterns),
 	}
 }
 
@@ -28,59 +30,72 @@ func NewLearningCore(cache *cache.RedisCache, cfg *config.ValidationConfig) *Lea
 // ============================================
 
 func (l *LearningCore) Learn(ctx context.Context, req *model.LearnRequest) (*model.LearnResponse, error) {
-	// Get existing companions for this object
-	companions, err := l.cache.GetCompanions(ctx, req.ObjectID)
-	if err != nil {
+	// Never learn source positions from garbage coordinates
+	if _, err := CheckCoordinateSanity(&l.cfg.Sanity, req.Latitude, req.Longitude, req.Accuracy); err != nil {
 		return nil, err
 	}
 
-	// Detect companions from current data
-	detectedCompanions := l.detectCompanions(req)
+	// Sources travelling with the object say nothing about where it is:
+	// they are reported as random and never learned
+	companions := l.observeCompanions(ctx, req)
 
-	// Add new companions to cache
-	for _, c := range detectedCompanions {
-		err := l.cache.AddCompanion(ctx, req.ObjectID, c.PointID, c.PointType)
-		if err != nil {
-			log.Printf("Warning: failed to add companion: %v", err)
-		}
-	}
-
-	// Update coordinates for detected companions
+	// Learned sources are reported by their mobility class; undetermined
+	// ones are in neither list
 	var stationarySources []string
 	var randomSources []string
+	classify := func(id string, mobility model.SourceMobility) bool {
+		switch mobility {
+		case model.SourceMobilityStationary:
+			stationarySources = append(stationarySources, id)
+		case model.SourceMobilityMobile:
+			randomSources = append(randomSources, id)
+			return false
+		}
+		return true
+	}
 
 	// Process WiFi
+	var fingerprintAPs []model.WifiAP
 	for _, w := range req.Wifi {
-		isCompanion := l.isCompanion(detectedCompanions, w.BSSID, model.PointTypeWifi)
-		l.updateWifiCoordinates(ctx, req, &w, isCompanion)
-		if isCompanion {
-			stationarySources = append(stationarySources, w.BSSID)
-		} else {
+		if companions[model.CompanionKey(model.PointTypeWifi, w.BSSID)] {
 			randomSources = append(randomSources, w.BSSID)
+			continue
+		}
+		if reason := l.wifiExclusion(&w); reason != "" {
+			randomSources = append(randomSources, w.BSSID)
+			l.recordExclusion(ctx, model.PointTypeWifi, w.BSSID, w.SSID, reason, false)
+			continue
+		}
+		if classify(w.BSSID, l.updateWifiCoordinates(ctx, req, &w)) {
+			fingerprintAPs = append(fingerprintAPs, w)
 		}
 	}
 
 	// Process Cell Towers
 	for _, c := range req.CellTowers {
-		key := keyFromCell(c.CellID, c.LAC)
-		isCompanion := l.isCompanion(detectedCompanions, key, model.PointTypeCell)
-		l.updateCellCoordinates(ctx, req, &c, isCompanion)
-		if isCompanion {
-			stationarySources = append(stationarySources, key)
-		} else {
+		key := c.Key().String()
+		if companions[model.CompanionKey(model.PointTypeCell, key)] {
 			randomSources = append(randomSources, key)
+			continue
 		}
+		classify(key, l.updateCellCoordinates(ctx, req, &c))
 	}
 
+	// Record which APs are seen together here
+	l.recordFingerprint(ctx, req, fingerprintAPs)
+
 	// Process Bluetooth
 	for _, b := range req.Bluetooth {
-		isCompanion := l.isCompanion(detectedCompanions, b.MAC, model.PointTypeBT)
-		l.updateBTCoordinates(ctx, req, &b, isCompanion)
-		if isCompanion {
-			stationarySources = append(stationarySources, b.MAC)
-		} else {
+		if companions[model.CompanionKey(model.PointTypeBT, b.MAC)] {
+			randomSources = append(randomSources, b.MAC)
+			continue
+		}
+		if reason := l.btExclusion(&b); reason != "" {
 			randomSources = append(randomSources, b.MAC)
+			l.recordExclusion(ctx, model.PointTypeBT, b.MAC, "", reason, false)
+			continue
 		}
+		classify(b.MAC, l.updateBTCoordinates(ctx, req, &b))
 	}
 
 	// Determine result
@@ -94,170 +109,244 @@ func (l *LearningCore) Learn(ctx context.Context, req *model.LearnRequest) (*mod
 }
 
 // ============================================
-// Companion Detection (Co-occurrence Analysis)
+// Update Cached Coordinates
 // ============================================
 
-func (l *LearningCore) detectCompanions(req *model.LearnRequest) []model.CompanionSource {
-	var sources []model.CompanionSource
+// learnedPosition is the part of a cached source entry that learning
+// updates, whatever the source type.
+type learnedPosition struct {
+	latitude    float64
+	longitude   float64
+	obsCount    int64
+	confidence  float64
+	stats       model.SourceStats
+	summary     model.PositionSummary
+	relocation  *model.RelocationCandidate
+	quarantined bool
+}
 
-	// WiFi sources
-	for _, w := range req.Wifi {
-		sources = append(sources, model.CompanionSource{
-			PointID:   w.BSSID,
-			PointType: model.PointTypeWifi,
-		})
+// learnPosition adds the request position, weighted by weight, to a
+// source's learned position; prev is nil for a new source.
+func (l *LearningCore) learnPosition(ctx context.Context, pointType model.PointType, pointID string, prev *learnedPosition, req *model.LearnRequest, weight float64) learnedPosition {
+	sample := model.PositionSample{Latitude: req.Latitude, Longitude: req.Longitude, Weight: weight}
+	if prev == nil {
+		return learnedPosition{
+			latitude:   req.Latitude,
+			longitude:  req.Longitude,
+			obsCount:   1,
+			confidence: 0.3,
+			stats:      l.learnStats(pointType, model.SourceStats{}, req.Latitude, req.Longitude),
+			summary:    l.addSample(model.PositionSummary{}, sample),
+		}
 	}
 
-	// Cell towers
-	for _, c := range req.CellTowers {
-		sources = append(sources, model.CompanionSource{
-			PointID:   keyFromCell(c.CellID, c.LAC),
-			PointType: model.PointTypeCell,
-		})
-	}
+	next := *prev
+	next.stats = seedStats(prev.stats, prev.latitude, prev.longitude)
+	next.summary = seedSummary(prev.summary, prev.latitude, prev.longitude, weight)
 
-	// Bluetooth
-	for _, b := range req.Bluetooth {
-		sources = append(sources, model.CompanionSource{
-			PointID:   b.MAC,
-			PointType: model.PointTypeBT,
-		})
+	// Far positions never drag the cached one; they may reveal a move
+	if l.isFar(pointType, prev, req) {
+		next = l.observeFar(ctx, pointType, pointID, next, req, sample)
+		l.updateMobileExclusion(ctx, pointType, pointID, prev.stats.Mobility, next.stats.Mobility)
+		return next
 	}
-
-	// Simple heuristic: devices seen together frequently are companions
-	// In production, this would use more sophisticated ML
-	return sources
+	next.relocation = nil
+	next.quarantined = false
+
+	// Robust estimate from the kept positions
+	next.summary = l.addSample(next.summary, sample)
+	next.latitude, next.longitude, next.summary = l.estimateSource(next.summary)
+	next.obsCount++
+	next.confidence = calculateConfidence(next.obsCount, next.summary.Spread, l.spreadScale(pointType))
+	next.stats = l.learnStats(pointType, next.stats, req.Latitude, req.Longitude)
+	l.updateMobileExclusion(ctx, pointType, pointID, prev.stats.Mobility, next.stats.Mobility)
+	return next
 }
 
-func (l *LearningCore) isCompanion(companions []model.CompanionSource, pointID string, pointType model.PointType) bool {
-	for _, c := range companions {
-		if c.PointID == pointID && c.PointType == pointType {
-			return true
+func (l *LearningCore) updateWifiCoordinates(ctx context.Context, req *model.LearnRequest, wifi *model.WifiAP) model.SourceMobility {
+	existing, _ := l.cache.GetWifi(ctx, wifi.BSSID)
+
+	var prev *learnedPosition
+	version := int64(1)
+	if existing != nil {
+		prev = &learnedPosition{
+			latitude:    existing.Latitude,
+			longitude:   existing.Longitude,
+			obsCount:    existing.ObsCount,
+			confidence:  existing.Confidence,
+			stats:       existing.SourceStats,
+			summary:     existing.PositionSummary,
+			relocation:  existing.Relocation,
+			quarantined: existing.Quarantined,
 		}
+		version = existing.Version + 1
 	}
-	return false
+	weight := sampleWeight(req.Accuracy, wifiRange(&l.cfg.Positioning, *wifi, 0))
+	pos := l.learnPosition(ctx, model.PointTypeWifi, wifi.BSSID, prev, req, weight)
+
+	l.cache.SetWifi(ctx, &model.CachedWifi{
+		BSSID:           wifi.BSSID,
+		Latitude:        pos.latitude,
+		Longitude:       pos.longitude,
+		LastSeen:        time.Now(),
+		Version:         version,
+		ObsCount:        pos.obsCount,
+		Confidence:      pos.confidence,
+		SourceStats:     pos.stats,
+		PositionSummary: pos.summary,
+		Relocation:      pos.relocation,
+		Quarantined:     pos.quarantined,
+	})
+	return pos.stats.Mobility
 }
 
-// ============================================
-// Update Cached Coordinates
-// ============================================
+func (l *LearningCore) updateCellCoordinates(ctx context.Context, req *model.LearnRequest, cell *model.CellTower) model.SourceMobility {
+	existing, _ := l.cache.GetCell(ctx, cell.Key())
+
+	var prev *learnedPosition
+	version := int64(1)
+	pci, earfcn := cell.PCI, cell.EARFCN
+	if existing != nil {
+		prev = &learnedPosition{
+			latitude:    existing.Latitude,
+			longitude:   existing.Longitude,
+			obsCount:    existing.ObsCount,
+			confidence:  existing.Confidence,
+			stats:       existing.SourceStats,
+			summary:     existing.PositionSummary,
+			relocation:  existing.Relocation,
+			quarantined: existing.Quarantined,
+		}
+		version = existing.Version + 1
+		if pci == nil {
+			pci = existing.PCI
+		}
+		if earfcn == nil {
+			earfcn = existing.EARFCN
+		}
+	}
+	weight := sampleWeight(req.Accuracy, cellRange(&l.cfg.Positioning, *cell, 0))
+	pos := l.learnPosition(ctx, model.PointTypeCell, cell.Key().String(), prev, req, weight)
+
+	l.cache.SetCell(ctx, &model.CachedCell{
+		Radio:           cell.Radio,
+		MCC:             cell.MCC,
+		MNC:             cell.MNC,
+		LAC:             cell.LAC,
+		CellID:          cell.CellID,
+		PCI:             pci,
+		EARFCN:          earfcn,
+		Latitude:        pos.latitude,
+		Longitude:       pos.longitude,
+		Version:         version,
+		ObsCount:        pos.obsCount,
+		Confidence:      pos.confidence,
+		SourceStats:     pos.stats,
+		PositionSummary: pos.summary,
+		Relocation:      pos.relocation,
+		Quarantined:     pos.quarantined,
+	})
+	return pos.stats.Mobility
+}
 
-func (l *LearningCore) updateWifiCoordinates(ctx context.Context, req *model.LearnRequest, wifi *model.WifiAP, isCompanion bool) {
-	existing, _ := l.cache.GetWifi(ctx, wifi.BSSID)
+func (l *LearningCore) updateBTCoordinates(ctx context.Context, req *model.LearnRequest, bt *model.BluetoothDev) model.SourceMobility {
+	existing, _ := l.cache.GetBT(ctx, bt.MAC)
 
-	if existing == nil {
-		// New WiFi - create entry
-		l.cache.SetWifi(ctx, &model.CachedWifi{
-			BSSID:      wifi.BSSID,
-			Latitude:   req.Latitude,
-			Longitude:  req.Longitude,
-			LastSeen:   time.Now(),
-			Version:    1,
-			ObsCount:   1,
-			Confidence: 0.3,
-		})
-	} else {
-		// Update existing - weighted average
-		weight := 0.1 // decay factor
-		if isCompanion {
-			weight = 0.2 // companion updates faster
+	var prev *learnedPosition
+	version := int64(1)
+	if existing != nil {
+		prev = &learnedPosition{
+			latitude:    existing.Latitude,
+			longitude:   existing.Longitude,
+			obsCount:    existing.ObsCount,
+			confidence:  existing.Confidence,
+			stats:       existing.SourceStats,
+			summary:     existing.PositionSummary,
+			relocation:  existing.Relocation,
+			quarantined: existing.Quarantined,
 		}
-
-		newLat := existing.Latitude*(1-weight) + req.Latitude*weight
-		newLon := existing.Longitude*(1-weight) + req.Longitude*weight
-		obsCount := existing.ObsCount + 1
-		confidence := calculateConfidence(obsCount)
-
-		l.cache.SetWifi(ctx, &model.CachedWifi{
-			BSSID:      wifi.BSSID,
-			Latitude:   newLat,
-			Longitude:  newLon,
-			LastSeen:   time.Now(),
-			Version:    existing.Version + 1,
-			ObsCount:   obsCount,
-			Confidence: confidence,
-		})
+		version = existing.Version + 1
 	}
+	weight := sampleWeight(req.Accuracy, bluetoothRange(&l.cfg.Positioning, *bt, 0))
+	pos := l.learnPosition(ctx, model.PointTypeBT, bt.MAC, prev, req, weight)
+
+	l.cache.SetBT(ctx, &model.CachedBT{
+		MAC:             bt.MAC,
+		Latitude:        pos.latitude,
+		Longitude:       pos.longitude,
+		LastSeen:        time.Now(),
+		Version:         version,
+		ObsCount:        pos.obsCount,
+		Confidence:      pos.confidence,
+		SourceStats:     pos.stats,
+		PositionSummary: pos.summary,
+		Relocation:      pos.relocation,
+		Quarantined:     pos.quarantined,
+	})
+	return pos.stats.Mobility
 }
 
-func (l *LearningCore) updateCellCoordinates(ctx context.Context, req *model.LearnRequest, cell *model.CellTower, isCompanion bool) {
-	key := keyFromCell(cell.CellID, cell.LAC)
-	existing, _ := l.cache.GetCell(ctx, cell.CellID, cell.LAC)
-
-	if existing == nil {
-		l.cache.SetCell(ctx, &model.CachedCell{
-			CellID:    cell.CellID,
-			LAC:       cell.LAC,
-			Latitude:  req.Latitude,
-			Longitude: req.Longitude,
-			Version:   1,
-			ObsCount:  1,
-			Confidence: 0.3,
-		})
-	} else {
-		weight := 0.1
-		if isCompanion {
-			weight = 0.2
-		}
+// ============================================
+// Stationarity
+// ============================================
 
-		newLat := existing.Latitude*(1-weight) + req.Latitude*weight
-		newLon := existing.Longitude*(1-weight) + req.Longitude*weight
-		obsCount := existing.ObsCount + 1
-		confidence := calculateConfidence(obsCount)
-
-		l.cache.SetCell(ctx, &model.CachedCell{
-			CellID:    cell.CellID,
-			LAC:       cell.LAC,
-			Latitude:  newLat,
-			Longitude: newLon,
-			Version:   existing.Version + 1,
-			ObsCount:  obsCount,
-			Confidence: confidence,
-		})
+// seedStats starts the statistics of an entry cached before they were kept
+// from its learned position.
+func seedStats(stats model.SourceStats, lat, lon float64) model.SourceStats {
+	if stats.Samples == 0 {
+		stats.Add(lat, lon)
 	}
+	return stats
 }
 
-func (l *LearningCore) updateBTCoordinates(ctx context.Context, req *model.LearnRequest, bt *model.BluetoothDev, isCompanion bool) {
-	existing, _ := l.cache.GetBT(ctx, bt.MAC)
+// learnStats folds a learned position into the statistics and
+// reclassifies the source.
+func (l *LearningCore) learnStats(pointType model.PointType, stats model.SourceStats, lat, lon float64) model.SourceStats {
+	stats.Add(lat, lon)
+	stats.Mobility = classifySource(&stats, l.cfg.Learning.MinObservations, l.stationaryDeviation(pointType))
+	return stats
+}
 
-	if existing == nil {
-		l.cache.SetBT(ctx, &model.CachedBT{
-			MAC:       bt.MAC,
-			Latitude:  req.Latitude,
-			Longitude: req.Longitude,
-			LastSeen:  time.Now(),
-			Version:   1,
-			ObsCount:  1,
-			Confidence: 0.3,
-		})
-	} else {
-		weight := 0.1
-		if isCompanion {
-			weight = 0.2
-		}
+// stationaryDeviation returns the largest standard deviation, in meters, of
+// the positions a stationary source of the type is learned at. A cell is
+// heard kilometers away, a BLE beacon only meters.
+func (l *LearningCore) stationaryDeviation(pointType model.PointType) float64 {
+	cfg := &l.cfg.Learning
+	switch pointType {
+	case model.PointTypeWifi:
+		return cfg.StationaryWifiMeters
+	case model.PointTypeBT:
+		return cfg.StationaryBLEMeters
+	case model.PointTypeCell:
+		return cfg.StationaryCellMeters
+	}
+	return 0
+}
 
-		newLat := existing.Latitude*(1-weight) + req.Latitude*weight
-		newLon := existing.Longitude*(1-weight) + req.Longitude*weight
-		obsCount := existing.ObsCount + 1
-		confidence := calculateConfidence(obsCount)
-
-		l.cache.SetBT(ctx, &model.CachedBT{
-			MAC:       bt.MAC,
-			Latitude:  newLat,
-			Longitude: newLon,
-			LastSeen:  time.Now(),
-			Version:   existing.Version + 1,
-			ObsCount:  obsCount,
-			Confidence: confidence,
-		})
+// classifySource marks a source STATIONARY when the positions it was learned
+// at deviate less than maxDeviation meters along either axis, MOBILE when
+// they deviate more, and UNDETERMINED until it has been learned minObs times.
+func classifySource(stats *model.SourceStats, minObs int64, maxDeviation float64) model.SourceMobility {
+	if stats.Samples < minObs {
+		return model.SourceMobilityUndetermined
+	}
+	varLat, varLon := stats.Variance()
+	scale := math.Cos(toRad(stats.MeanLat))
+	deviation := math.Sqrt(math.Max(varLat, varLon*scale*scale)) * earthRadiusMeters * math.Pi / 180
+	if deviation <= maxDeviation {
+		return model.SourceMobilityStationary
 	}
+	return model.SourceMobilityMobile
 }
 
 // ============================================
 // Helpers
 // ============================================
 
-func calculateConfidence(obsCount int64) float64 {
+// calculateConfidence scores a learned position by how often it was
+// learned and how far the learned positions scatter (spread, in meters);
+// a spread of scale halves the confidence.
+func calculateConfidence(obsCount int64, spread, scale float64) float64 {
 	// Confidence grows logarithmically with observations
 	// Max confidence ~0.95 at 1000 observations
 	const maxObs = 1000.0
@@ -268,13 +357,12 @@ func calculateConfidence(obsCount int64) float64 {
 	}
 
 	conf := maxConf * (1 - math.Exp(-float64(obsCount)/maxObs*5))
+	if scale > 0 {
+		conf *= scale / (scale + spread)
+	}
 	return conf
 }
 
-func keyFromCell(cellID uint32, lac uint32) string {
-	return string(rune(cellID)) + ":" + string(rune(lac))
-}
-
 func (l *LearningCore) determineLearningResult(stationaryCount, randomCount int) model.LearningResult {
 	if stationaryCount == 0 && randomCount == 0 {
 		return model.LearningResultNeedMoreData
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestClassifySource(t *testing.T) {
+	// Statistics of positions east/north meters from the test origin
+	stats := func(offsets ...[2]float64) *model.SourceStats {
+		s := &model.SourceStats{}
+		for _, o := range offsets {
+			s.Add(offsetLatLon(testLat, testLon, o[0], o[1]))
+		}
+		return s
+	}
+
+	tests := []struct {
+		name         string
+		stats        *model.SourceStats
+		maxDeviation float64
+		want         model.SourceMobility
+	}{
+		{
+			name:         "too few observations",
+			stats:        stats([2]float64{0, 0}, [2]float64{2000, 0}),
+			maxDeviation: 150,
+			want:         model.SourceMobilityUndetermined,
+		},
+		{
+			name:         "AP heard around its place",
+			stats:        stats([2]float64{0, 0}, [2]float64{40, -30}, [2]float64{-50, 20}, [2]float64{10, 60}),
+			maxDeviation: 150,
+			want:         model.SourceMobilityStationary,
+		},
+		{
+			name:         "AP heard across the district",
+			stats:        stats([2]float64{0, 0}, [2]float64{0, 600}, [2]float64{0, -600}, [2]float64{0, 300}),
+			maxDeviation: 150,
+			want:         model.SourceMobilityMobile,
+		},
+		{
+			name:         "east-west spread is measured in meters",
+			stats:        stats([2]float64{0, 0}, [2]float64{400, 0}, [2]float64{-400, 0}, [2]float64{200, 0}),
+			maxDeviation: 150,
+			want:         model.SourceMobilityMobile,
+		},
+		{
+			name:         "cell heard kilometers around",
+			stats:        stats([2]float64{0, 0}, [2]float64{3000, 0}, [2]float64{-2000, 2500}, [2]float64{0, -3000}),
+			maxDeviation: 8000,
+			want:         model.SourceMobilityStationary,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifySource(tt.stats, 3, tt.maxDeviation); got != tt.want {
+				t.Errorf("classifySource = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLearnMobility(t *testing.T) {
+	l := newTestLearningCore(t)
+	v := &ValidationCore{cache: l.cache, cfg: l.cfg}
+	ctx := context.Background()
+	t0 := time.Now().Unix() - 24*3600
+
+	const ap, beacon = "00:1a:2b:00:00:01", "c0:1a:7d:00:00:01"
+	wifi := []model.WifiAP{{BSSID: ap, RSSI: -60}}
+	ble := []model.BluetoothDev{{MAC: beacon, RSSI: -70}}
+
+	// The AP is learned within meters of one place; the beacon a few
+	// hundred meters around, never far enough to look like a move
+	learn := func(east []float64, wifi []model.WifiAP, ble []model.BluetoothDev) *model.LearnResponse {
+		var resp *model.LearnResponse
+		for i, e := range east {
+			req := learnAt("probe-1", e, 0, t0+int64(i)*3600)
+			req.Wifi, req.Bluetooth = wifi, ble
+			var err error
+			if resp, err = l.Learn(ctx, &req); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp
+	}
+
+	if resp := learn([]float64{0, 20, -20, 10, -10}, wifi, nil); len(resp.StationarySources) != 1 || len(resp.RandomSources) != 0 {
+		t.Errorf("AP: stationary/random = %v/%v, want stationary", resp.StationarySources, resp.RandomSources)
+	}
+	if resp := learn([]float64{0, 150, -150, 120, -120}, nil, ble); len(resp.StationarySources) != 0 || len(resp.RandomSources) != 1 {
+		t.Errorf("beacon: stationary/random = %v/%v, want random", resp.StationarySources, resp.RandomSources)
+	}
+
+	if conf, fixes, _ := v.checkWifi(ctx, wifi); conf <= 0 || len(fixes) != 1 {
+		t.Errorf("stationary AP: confidence %v from %d fixes, want it used", conf, len(fixes))
+	}
+	if conf, fixes, _ := v.checkBluetooth(ctx, ble); conf != 0 || len(fixes) != 0 {
+		t.Errorf("mobile beacon: confidence %v from %d fixes, want it ignored", conf, len(fixes))
+	}
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"math"
+
+	"coordinate-validator/internal/cache"
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Source-based Position Estimation
+// ============================================
+
+// sourceFix is a cached source position seen in the current scan.
+type sourceFix struct {
+	pointType  model.PointType
+	latitude   float64
+	longitude  float64
+	distance   float64 // expected distance to the device in meters
+	radius     float64 // max distance to the device in meters
+	confidence float64
+}
+
+func newSourceFix(pointType model.PointType, lat, lon float64, rng distanceRange, confidence float64) sourceFix {
+	return sourceFix{
+		pointType:  pointType,
+		latitude:   lat,
+		longitude:  lon,
+		distance:   rng.expected,
+		radius:     rng.max,
+		confidence: confidence,
+	}
+}
+
+// estimatePosition computes a weighted centroid of the given fixes. Sources
+// weigh by confidence and by the inverse square of their expected distance,
+// so a strong WiFi AP outweighs a distant cell. The returned radius covers
+// the max distance of every source plus its distance from the centroid, so
+// widely scattered sources produce a wide estimate.
+func estimatePosition(fixes []sourceFix, minSources int) *model.PositionEstimate {
+	if len(fixes) == 0 {
+		return nil
+	}
+
+	var sumW, sumLat, sumLon float64
+	weights := make([]float64, len(fixes))
+	for i, f := range fixes {
+		d := math.Max(f.distance, 1)
+		w := math.Max(f.confidence, 0.05) / (d * d)
+		weights[i] = w
+		sumW += w
+		sumLat += f.latitude * w
+		sumLon += f.longitude * w
+	}
+
+	lat := sumLat / sumW
+	lon := sumLon / sumW
+
+	// Weighted mean of (source max distance + distance to centroid)
+	var radius float64
+	for i, f := range fixes {
+		d := HaversineDistance(lat, lon, f.latitude, f.longitude) * 1000
+		radius += (f.radius + d) * weights[i]
+	}
+	radius /= sumW
+
+	// Too few sources to triangulate - widen the estimate
+	if len(fixes) < minSources {
+		radius *= 2
+	}
+
+	return &model.PositionEstimate{
+		Latitude:  lat,
+		Longitude: lon,
+		Radius:    radius,
+		Sources:   len(fixes),
+	}
+}
+
+// fuseAccuracy combines the reported accuracy with the radius of an
+// agreeing source estimate as two independent errors.
+func fuseAccuracy(accuracy float32, radius float64) float32 {
+	a := float64(accuracy)
+	if a <= 0 || radius <= 0 {
+		return accuracy
+	}
+	return float32(1 / math.Sqrt(1/(a*a)+1/(radius*radius)))
+}
+
+// estimatePenalty returns a confidence multiplier in (0, 1] for a reported
+// fix that lies distance meters from the estimate. Fixes inside the estimate
+// radius (extended by the reported accuracy) are not penalized.
+func estimatePenalty(distance, radius float64, accuracy float32) float64 {
+	allowed := radius + math.Max(float64(accuracy), 0)
+	if distance <= allowed {
+		return 1.0
+	}
+	penalty := allowed / distance
+	if penalty < 0.1 {
+		penalty = 0.1
+	}
+	return penalty
+}
+
+// ============================================
+// Path Loss
+// ============================================
+
+// distanceRange is the distance to a source implied by its signal.
+type distanceRange struct {
+	expected, min, max float64 // meters
+}
+
+// pathLossRange inverts a log-distance path loss model. The range covers
+// sigmas shadowing deviations on either side of the expected distance.
+func pathLossRange(m *config.PathLossConfig, rssi int32, sigmas float64) distanceRange {
+	at := func(dbm float64) float64 {
+		d := m.RefDistanceMeters * math.Pow(10, (m.RefRSSI-dbm)/(10*m.Exponent))
+		return math.Min(d, m.MaxDistanceMeters)
+	}
+	r := float64(rssi)
+	return distanceRange{
+		expected: at(r),
+		min:      at(r + sigmas*m.SigmaDB),
+		max:      at(r - sigmas*m.SigmaDB),
+	}
+}
+
+// sourceRSSI returns the RSSI of a source. EGTS sources carry a signal
+// level instead; 0 means neither was reported.
+func sourceRSSI(rssi, eid int32) int32 {
+	if rssi == 0 && eid != 0 {
+		return cache.ConvertEIDToRSSI(eid)
+	}
+	return rssi
+}
+
+// signalRange returns the distance range for a source. Sources without
+// signal strength get [0, fallback].
+func signalRange(m *config.PathLossConfig, rssi, eid int32, sigmas, fallback float64) distanceRange {
+	rssi = sourceRSSI(rssi, eid)
+	if rssi == 0 {
+		return distanceRange{expected: fallback, max: fallback}
+	}
+	return pathLossRange(m, rssi, sigmas)
+}
+
+// wifiRange returns the distance range of an AP. A learned coverage radius,
+// if any, replaces the default for APs reported without RSSI.
+func wifiRange(p *config.PositioningConfig, w model.WifiAP, coverage float64) distanceRange {
+	m := &p.PathLossWifi24
+	if w.Frequency >= 4900 {
+		m = &p.PathLossWifi5
+	}
+	return signalRange(m, w.RSSI, w.EID, p.RangeSigmas, coverageOr(coverage, p.RadiusWifiMeters))
+}
+
+func bluetoothRange(p *config.PositioningConfig, b model.BluetoothDev, coverage float64) distanceRange {
+	return signalRange(&p.PathLossBLE, b.RSSI, b.EID, p.RangeSigmas, coverageOr(coverage, p.RadiusBLEMeters))
+}
+
+func cellRange(p *config.PositioningConfig, c model.CellTower, coverage float64) distanceRange {
+	return timingAdvanceRange(c, signalRange(&p.PathLossCell, c.RSSI, c.EID, p.RangeSigmas, coverageOr(coverage, p.RadiusCellMeters)))
+}
+
+func coverageOr(coverage, fallback float64) float64 {
+	if coverage > 0 {
+		return coverage
+	}
+	return fallback
+}
+
+// Distance covered by one timing advance step
+const (
+	gsmTimingAdvanceMeters = 553.5
+	lteTimingAdvanceMeters = 78.12
+)
+
+// timingAdvanceRange narrows a cell's distance range to the ring implied by
+// the reported timing advance, if any. The timing advance is far more
+// precise than RSSI, so it wins when the two disagree.
+func timingAdvanceRange(c model.CellTower, r distanceRange) distanceRange {
+	if c.TimingAdvance == nil || *c.TimingAdvance < 0 {
+		return r
+	}
+	var step float64
+	switch c.Radio {
+	case model.RadioGSM:
+		step = gsmTimingAdvanceMeters
+	case model.RadioLTE:
+		step = lteTimingAdvanceMeters
+	default:
+		return r
+	}
+	lo := float64(*c.TimingAdvance) * step
+	hi := lo + step
+	if r.max < lo || r.min > hi {
+		return distanceRange{expected: (lo + hi) / 2, min: lo, max: hi}
+	}
+	return distanceRange{
+		expected: math.Max(lo, math.Min(hi, r.expected)),
+		min:      math.Max(lo, r.min),
+		max:      math.Min(hi, r.max),
+	}
+}
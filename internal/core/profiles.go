@@ -0,0 +1,190 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"coordinate-validator/internal/cache"
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Device Profile Registry
+// ============================================
+
+// ProfileRegistry resolves per device-class validation limits. A device gets
+// its explicitly assigned profile, else the profile of its longest matching
+// ID prefix, else the default profile built from the validation config.
+// Profiles and prefixes are cached in memory and reloaded every
+// RefreshInterval; only the per-device assignment is read on each lookup.
+type ProfileRegistry struct {
+	cache *cache.RedisCache
+	cfg   *config.ValidationConfig
+	table *snapshot[*profileTable]
+}
+
+type profileTable struct {
+	profiles map[string]model.DeviceProfile // by name
+	prefixes map[string]string              // device ID prefix -> profile name
+}
+
+func NewProfileRegistry(cache *cache.RedisCache, cfg *config.ValidationConfig) *ProfileRegistry {
+	r := &ProfileRegistry{
+		cache: cache,
+		cfg:   cfg,
+	}
+	r.table = newSnapshot("device profiles", cfg.Profiles.RefreshInterval, r.loadTable)
+	return r
+}
+
+func (r *ProfileRegistry) loadTable(ctx context.Context) (*profileTable, error) {
+	t := &profileTable{}
+	profiles, err := r.cache.ListDeviceProfiles(ctx)
+	if err != nil {
+		return t, err
+	}
+	prefixes, err := r.cache.GetProfilePrefixes(ctx)
+	if err != nil {
+		return t, err
+	}
+
+	t.profiles = make(map[string]model.DeviceProfile, len(profiles))
+	for _, p := range profiles {
+		t.profiles[p.Name] = p
+	}
+	t.prefixes = prefixes
+	return t, nil
+}
+
+// Default returns the profile built from the global validation config.
+func (r *ProfileRegistry) Default() *model.DeviceProfile {
+	return &model.DeviceProfile{
+		Name:               model.DefaultProfileName,
+		MaxSpeedKmH:        r.cfg.MaxSpeedKmH,
+		MaxAccelerationMS2: r.cfg.Trajectory.MaxAccelerationMS2,
+		MaxTimeDiff:        r.cfg.MaxTimeDiff,
+	}
+}
+
+// Resolve returns the effective profile for a device. Lookup failures fall
+// back to the default profile so validation never blocks on the registry.
+func (r *ProfileRegistry) Resolve(ctx context.Context, deviceID string) *model.DeviceProfile {
+	name, err := r.cache.GetDeviceProfileName(ctx, deviceID)
+	if err != nil {
+		return r.Default()
+	}
+
+	table := r.table.get(ctx)
+	if name == "" {
+		name = longestPrefixMatch(table.prefixes, deviceID)
+	}
+
+	if name == "" || name == model.DefaultProfileName {
+		return r.Default()
+	}
+
+	profile, ok := table.profiles[name]
+	if !ok {
+		return r.Default()
+	}
+	return r.withDefaults(&profile)
+}
+
+// withDefaults fills unset limits from the default profile.
+func (r *ProfileRegistry) withDefaults(p *model.DeviceProfile) *model.DeviceProfile {
+	def := r.Default()
+	out := *p
+	if out.MaxSpeedKmH <= 0 {
+		out.MaxSpeedKmH = def.MaxSpeedKmH
+	}
+	if out.MaxAccelerationMS2 <= 0 {
+		out.MaxAccelerationMS2 = def.MaxAccelerationMS2
+	}
+	if out.MaxTimeDiff <= 0 {
+		out.MaxTimeDiff = def.MaxTimeDiff
+	}
+	return &out
+}
+
+func longestPrefixMatch(prefixes map[string]string, deviceID string) string {
+	var best, name string
+	for prefix, n := range prefixes {
+		if strings.HasPrefix(deviceID, prefix) && len(prefix) > len(best) {
+			best = prefix
+			name = n
+		}
+	}
+	return name
+}
+
+// ============================================
+// Management
+// ============================================
+
+func (r *ProfileRegistry) List(ctx context.Context) ([]model.DeviceProfile, error) {
+	return r.cache.ListDeviceProfiles(ctx)
+}
+
+func (r *ProfileRegistry) Set(ctx context.Context, profile *model.DeviceProfile) error {
+	if profile.Name == "" || profile.Name == model.DefaultProfileName {
+		return fmt.Errorf("invalid profile name %q", profile.Name)
+	}
+	if profile.MaxSpeedKmH < 0 || profile.MaxAccelerationMS2 < 0 || profile.MaxTimeDiff < 0 {
+		return fmt.Errorf("profile %q: limits must not be negative", profile.Name)
+	}
+	if err := r.cache.SetDeviceProfile(ctx, profile); err != nil {
+		return err
+	}
+	r.table.invalidate()
+	return nil
+}
+
+func (r *ProfileRegistry) Delete(ctx context.Context, name string) error {
+	if err := r.cache.DeleteDeviceProfile(ctx, name); err != nil {
+		return err
+	}
+	r.table.invalidate()
+	return nil
+}
+
+// Assign binds a profile to a device ID or, if deviceID is empty, to an ID
+// prefix. An empty profile name removes the binding.
+func (r *ProfileRegistry) Assign(ctx context.Context, deviceID, prefix, name string) error {
+	if name != "" && name != model.DefaultProfileName {
+		profile, err := r.cache.GetDeviceProfile(ctx, name)
+		if err != nil {
+			return err
+		}
+		if profile == nil {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+	}
+
+	switch {
+	case deviceID != "":
+		return r.cache.SetDeviceProfileName(ctx, deviceID, name)
+	case prefix != "":
+		if err := r.cache.SetProfilePrefix(ctx, prefix, name); err != nil {
+			return err
+		}
+		r.table.invalidate()
+		return nil
+	default:
+		return fmt.Errorf("device_id or prefix is required")
+	}
+}
+
+// Assignments returns device ID and prefix bindings.
+func (r *ProfileRegistry) Assignments(ctx context.Context) (map[string]string, map[string]string, error) {
+	devices, err := r.cache.GetDeviceProfileNames(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	prefixes, err := r.cache.GetProfilePrefixes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return devices, prefixes, nil
+}
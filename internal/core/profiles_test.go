@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestProfileRegistryResolve(t *testing.T) {
+	v, _ := newTestCore(t)
+	r := v.profiles
+	ctx := context.Background()
+
+	for _, p := range []model.DeviceProfile{
+		{Name: "pedestrian", MaxSpeedKmH: 15},
+		{Name: "truck", MaxSpeedKmH: 110, MaxTimeDiff: time.Hour},
+		{Name: "van", MaxSpeedKmH: 130},
+	} {
+		if err := r.Set(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, a := range []struct{ device, prefix, profile string }{
+		{prefix: "walker-", profile: "pedestrian"},
+		{prefix: "fleet-", profile: "van"},
+		{prefix: "fleet-heavy-", profile: "truck"},
+		{device: "fleet-7", profile: "pedestrian"},
+		{device: "fleet-heavy-2", profile: model.DefaultProfileName},
+	} {
+		if err := r.Assign(ctx, a.device, a.prefix, a.profile); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	def := r.Default()
+	tests := []struct {
+		name        string
+		deviceID    string
+		profile     string
+		maxSpeed    float64
+		maxTimeDiff time.Duration
+	}{
+		{"no assignment", "car-1", model.DefaultProfileName, def.MaxSpeedKmH, def.MaxTimeDiff},
+		{"prefix", "walker-1", "pedestrian", 15, def.MaxTimeDiff},
+		{"longest prefix", "fleet-heavy-1", "truck", 110, time.Hour},
+		{"device over prefix", "fleet-7", "pedestrian", 15, def.MaxTimeDiff},
+		{"device assigned the default", "fleet-heavy-2", model.DefaultProfileName, def.MaxSpeedKmH, def.MaxTimeDiff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Resolve(ctx, tt.deviceID)
+			if got.Name != tt.profile || got.MaxSpeedKmH != tt.maxSpeed || got.MaxTimeDiff != tt.maxTimeDiff {
+				t.Errorf("Resolve(%q) = %s %.0f km/h %v, want %s %.0f km/h %v",
+					tt.deviceID, got.Name, got.MaxSpeedKmH, got.MaxTimeDiff, tt.profile, tt.maxSpeed, tt.maxTimeDiff)
+			}
+		})
+	}
+}
+
+func TestProfileRegistryCachesTable(t *testing.T) {
+	v, _ := newTestCore(t)
+	r := v.profiles
+	ctx := context.Background()
+
+	if err := r.Set(ctx, &model.DeviceProfile{Name: "pedestrian", MaxSpeedKmH: 15}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Assign(ctx, "", "walker-", "pedestrian"); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Resolve(ctx, "walker-1"); got.MaxSpeedKmH != 15 {
+		t.Fatalf("max speed = %.0f, want 15", got.MaxSpeedKmH)
+	}
+
+	// Written behind the registry's back: not seen until the next reload
+	if err := v.cache.SetDeviceProfile(ctx, &model.DeviceProfile{Name: "pedestrian", MaxSpeedKmH: 20}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Resolve(ctx, "walker-1"); got.MaxSpeedKmH != 15 {
+		t.Errorf("max speed = %.0f before the reload, want the cached 15", got.MaxSpeedKmH)
+	}
+
+	// Changes through the registry are seen at once
+	if err := r.Set(ctx, &model.DeviceProfile{Name: "pedestrian", MaxSpeedKmH: 25}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Resolve(ctx, "walker-1"); got.MaxSpeedKmH != 25 {
+		t.Errorf("max speed = %.0f after Set, want 25", got.MaxSpeedKmH)
+	}
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// ============================================
+// Refreshed Registry Snapshots
+// ============================================
+
+// snapshot caches a registry loaded from Redis for use on the validation
+// path. Once loaded, lookups never wait on Redis: the first caller to find
+// it stale reloads it while the others keep the previous value. A failed
+// reload keeps the previous value until the next interval.
+type snapshot[T any] struct {
+	name     string // for log messages
+	interval time.Duration
+	load     func(ctx context.Context) (T, error) // on error, the value to start from
+
+	mu         sync.Mutex // guards the fields below
+	value      T
+	loaded     bool
+	loadedAt   time.Time
+	generation int // bumped by invalidate
+
+	reloading sync.Mutex // held while loading
+}
+
+func newSnapshot[T any](name string, interval time.Duration, load func(ctx context.Context) (T, error)) *snapshot[T] {
+	return &snapshot[T]{name: name, interval: interval, load: load}
+}
+
+// get returns the current value, reloading it when stale.
+func (s *snapshot[T]) get(ctx context.Context) T {
+	s.mu.Lock()
+	value, loaded := s.value, s.loaded
+	fresh := loaded && !s.loadedAt.IsZero() && time.Since(s.loadedAt) < s.interval
+	s.mu.Unlock()
+	if fresh {
+		return value
+	}
+
+	if loaded {
+		// Someone is already reloading: use the previous value meanwhile
+		if !s.reloading.TryLock() {
+			return value
+		}
+	} else {
+		// Nothing to fall back on yet: wait for the first load
+		s.reloading.Lock()
+		s.mu.Lock()
+		if s.loaded {
+			value = s.value
+			s.mu.Unlock()
+			s.reloading.Unlock()
+			return value
+		}
+		s.mu.Unlock()
+	}
+	defer s.reloading.Unlock()
+
+	s.mu.Lock()
+	generation := s.generation
+	s.mu.Unlock()
+
+	next, err := s.load(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		log.Printf("Warning: failed to reload %s: %v", s.name, err)
+		if !s.loaded {
+			s.value, s.loaded = next, true
+		}
+		s.loadedAt = time.Now()
+		return s.value
+	}
+	s.value, s.loaded = next, true
+	// A change made during the load may be missing from it
+	if s.generation == generation {
+		s.loadedAt = time.Now()
+	}
+	return s.value
+}
+
+// invalidate forces a reload on the next lookup.
+func (s *snapshot[T]) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.generation++
+	s.mu.Unlock()
+}
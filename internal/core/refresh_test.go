@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSnapshotReload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cached within the interval", func(t *testing.T) {
+		var loads int32
+		s := newSnapshot("test", time.Hour, func(context.Context) (int32, error) {
+			return atomic.AddInt32(&loads, 1), nil
+		})
+		for i := 0; i < 3; i++ {
+			if got := s.get(ctx); got != 1 {
+				t.Errorf("get = %d, want 1", got)
+			}
+		}
+		s.invalidate()
+		if got := s.get(ctx); got != 2 {
+			t.Errorf("get after invalidate = %d, want 2", got)
+		}
+	})
+
+	t.Run("lookups don't wait on a reload", func(t *testing.T) {
+		var loads int32
+		release := make(chan struct{})
+		started := make(chan struct{})
+		s := newSnapshot("test", time.Hour, func(context.Context) (int32, error) {
+			n := atomic.AddInt32(&loads, 1)
+			if n > 1 {
+				close(started)
+				<-release
+			}
+			return n, nil
+		})
+		s.get(ctx)
+		s.invalidate()
+
+		done := make(chan int32)
+		go func() { done <- s.get(ctx) }()
+		<-started
+
+		// The reload is blocked: other lookups get the previous value
+		if got := s.get(ctx); got != 1 {
+			t.Errorf("get during reload = %d, want the previous 1", got)
+		}
+		close(release)
+		if got := <-done; got != 2 {
+			t.Errorf("reloading get = %d, want 2", got)
+		}
+		if got := s.get(ctx); got != 2 {
+			t.Errorf("get after reload = %d, want 2", got)
+		}
+	})
+
+	t.Run("change during a reload forces another", func(t *testing.T) {
+		var loads int32
+		var s *snapshot[int32]
+		s = newSnapshot("test", time.Hour, func(context.Context) (int32, error) {
+			n := atomic.AddInt32(&loads, 1)
+			if n == 1 {
+				s.invalidate()
+			}
+			return n, nil
+		})
+		s.get(ctx)
+		if got := s.get(ctx); got != 2 {
+			t.Errorf("get = %d, want a reload to 2", got)
+		}
+	})
+
+	t.Run("failed loads", func(t *testing.T) {
+		fail := true
+		s := newSnapshot("test", time.Hour, func(context.Context) (string, error) {
+			if fail {
+				return "fallback", errors.New("down")
+			}
+			return "loaded", nil
+		})
+		if got := s.get(ctx); got != "fallback" {
+			t.Errorf("first failed load = %q, want the fallback", got)
+		}
+
+		fail = false
+		s.invalidate()
+		if got := s.get(ctx); got != "loaded" {
+			t.Errorf("get = %q, want loaded", got)
+		}
+
+		fail = true
+		s.invalidate()
+		if got := s.get(ctx); got != "loaded" {
+			t.Errorf("failed reload = %q, want the previous value", got)
+		}
+	})
+}
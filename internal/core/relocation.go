@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Source Relocation
+// ============================================
+//
+// When a router moves across town, every new position is far from the
+// cached one. Those positions are kept aside instead of being averaged in:
+// after a few of them the source is quarantined (validation ignores it), and
+// once enough agreeing far positions span long enough the move is recorded
+// and the source is re-learned from scratch at its new place. A near
+// position in between means the far ones were noise and lifts quarantine.
+// A source already classified MOBILE has no place to move from and is never
+// relocated.
+
+// relocationDistance returns the distance beyond which a learned position
+// counts towards a move, 0 if moves aren't tracked for the type.
+func (l *LearningCore) relocationDistance(pointType model.PointType) float64 {
+	cfg := &l.cfg.Relocation
+	switch pointType {
+	case model.PointTypeWifi:
+		return cfg.DistanceWifiMeters
+	case model.PointTypeBT:
+		return cfg.DistanceBLEMeters
+	case model.PointTypeCell:
+		return cfg.DistanceCellMeters
+	}
+	return 0
+}
+
+func (l *LearningCore) isFar(pointType model.PointType, prev *learnedPosition, req *model.LearnRequest) bool {
+	limit := l.relocationDistance(pointType)
+	return limit > 0 && HaversineDistance(prev.latitude, prev.longitude, req.Latitude, req.Longitude)*1000 > limit
+}
+
+// observeFar adds a far position to the relocation candidate and re-learns
+// the source at the candidate once the move is confirmed.
+func (l *LearningCore) observeFar(ctx context.Context, pointType model.PointType, pointID string, next learnedPosition, req *model.LearnRequest, sample model.PositionSample) learnedPosition {
+	cfg := &l.cfg.Relocation
+	limit := l.relocationDistance(pointType)
+
+	// The variance sees every position, or a mobile source would look still
+	mobile := next.stats.Mobility == model.SourceMobilityMobile
+	next.stats = l.learnStats(pointType, next.stats, req.Latitude, req.Longitude)
+
+	// A move under way continues even though the far positions it adds
+	// make the source look mobile
+	if mobile && next.relocation == nil {
+		return next
+	}
+
+	// Far positions that disagree with each other start a new candidate
+	c := model.RelocationCandidate{Since: req.Timestamp}
+	if prev := next.relocation; prev != nil &&
+		HaversineDistance(prev.Latitude, prev.Longitude, req.Latitude, req.Longitude)*1000 <= limit {
+		c = *prev
+	}
+	c.Count++
+	c.Latitude += (req.Latitude - c.Latitude) / float64(c.Count)
+	c.Longitude += (req.Longitude - c.Longitude) / float64(c.Count)
+	if req.Timestamp < c.Since {
+		c.Since = req.Timestamp
+	}
+	next.relocation = &c
+	next.quarantined = c.Count >= cfg.QuarantineAfter
+
+	if c.Count < cfg.MinObservations || req.Timestamp-c.Since < int64(cfg.MinDuration.Seconds()) {
+		return next
+	}
+
+	event := &model.RelocationEvent{
+		PointID:        pointID,
+		PointType:      pointType,
+		FromLatitude:   next.latitude,
+		FromLongitude:  next.longitude,
+		ToLatitude:     c.Latitude,
+		ToLongitude:    c.Longitude,
+		DistanceMeters: HaversineDistance(next.latitude, next.longitude, c.Latitude, c.Longitude) * 1000,
+		Observations:   c.Count,
+		FirstSeen:      c.Since,
+		DetectedAt:     time.Now().Unix(),
+	}
+	log.Printf("Source %s %s relocated %.0f m after %d observations", pointType, pointID, event.DistanceMeters, c.Count)
+	if err := l.cache.PushRelocation(ctx, event, cfg.HistorySize); err != nil {
+		log.Printf("Warning: failed to record relocation of %s %s: %v", pointType, pointID, err)
+	}
+
+	// Re-learn from scratch at the new place
+	sample.Latitude, sample.Longitude = c.Latitude, c.Longitude
+	return learnedPosition{
+		latitude:   c.Latitude,
+		longitude:  c.Longitude,
+		obsCount:   c.Count,
+		confidence: calculateConfidence(c.Count, 0, 0),
+		stats:      l.learnStats(pointType, model.SourceStats{}, c.Latitude, c.Longitude),
+		summary:    l.addSample(model.PositionSummary{}, sample),
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestLearnRelocation(t *testing.T) {
+	l := newTestLearningCore(t)
+	ctx := context.Background()
+	t0 := time.Now().Unix() - 48*3600
+
+	settled := []float64{0, 10, -10, 5, -5}
+	wandering := []float64{0, 300, -300, 250, -250}
+	moved := []float64{2000, 2010, 1990, 2005, 1995}
+	concat := func(parts ...[]float64) []float64 {
+		var all []float64
+		for _, p := range parts {
+			all = append(all, p...)
+		}
+		return all
+	}
+
+	tests := []struct {
+		name        string
+		east        []float64 // learned positions, an hour apart
+		wantEast    float64   // cached position
+		mobility    model.SourceMobility
+		quarantined bool
+		relocations int
+		listed      bool // excluded as MOBILE
+	}{
+		{
+			name:     "settled router",
+			east:     settled,
+			mobility: model.SourceMobilityStationary,
+		},
+		{
+			name:        "far positions quarantine",
+			east:        concat(settled, moved[:2]),
+			mobility:    model.SourceMobilityMobile,
+			quarantined: true,
+			listed:      true,
+		},
+		{
+			name:     "near position lifts quarantine",
+			east:     concat(settled, moved[:2], settled[:1]),
+			mobility: model.SourceMobilityMobile,
+			listed:   true,
+		},
+		{
+			name:        "router moved across town",
+			east:        concat(settled, moved),
+			wantEast:    2000,
+			mobility:    model.SourceMobilityUndetermined,
+			relocations: 1,
+		},
+		{
+			name:     "mobile source isn't relocated",
+			east:     concat(wandering, moved),
+			mobility: model.SourceMobilityMobile,
+			listed:   true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bssid := fmt.Sprintf("00:1a:2b:00:00:%02x", i)
+			for j, east := range tt.east {
+				// Different probes, or the AP would follow one as a companion
+				req := learnAt(fmt.Sprintf("probe-%d", j), east, 0, t0+int64(j)*3600)
+				req.Wifi = []model.WifiAP{{BSSID: bssid, RSSI: -60}}
+				if _, err := l.Learn(ctx, &req); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cached, err := l.cache.GetWifi(ctx, bssid)
+			if err != nil || cached == nil {
+				t.Fatalf("cached = %v, err = %v", cached, err)
+			}
+			wantLat, wantLon := offsetLatLon(testLat, testLon, tt.wantEast, 0)
+			if d := HaversineDistance(cached.Latitude, cached.Longitude, wantLat, wantLon) * 1000; d > 100 {
+				t.Errorf("cached position is %.0f m from %.0f m east", d, tt.wantEast)
+			}
+			if cached.Mobility != tt.mobility || cached.Quarantined != tt.quarantined {
+				t.Errorf("mobility/quarantined = %s/%v, want %s/%v", cached.Mobility, cached.Quarantined, tt.mobility, tt.quarantined)
+			}
+
+			events, err := l.cache.GetRelocations(ctx, model.PointTypeWifi, bssid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != tt.relocations {
+				t.Errorf("relocations = %d, want %d", len(events), tt.relocations)
+			}
+
+			excluded, err := l.cache.ListExcludedSources(ctx, model.PointTypeWifi, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listed := false
+			for _, ex := range excluded {
+				if ex.PointID == bssid && ex.Reason == model.ExclusionMobile {
+					listed = true
+				}
+			}
+			if listed != tt.listed {
+				t.Errorf("listed as MOBILE = %v, want %v", listed, tt.listed)
+			}
+		})
+	}
+}
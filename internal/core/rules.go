@@ -0,0 +1,309 @@
+package core
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+	"sync"
+
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Validation Rule Chain
+// ============================================
+
+// Rule is one check of the validation chain. Rules run in the configured
+// order and share a RuleContext, so later rules can read what earlier ones
+// wrote to the response.
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error)
+}
+
+// RuleContext carries the request being validated through the chain.
+type RuleContext struct {
+	Request *model.CoordinateRequest // timestamp corrected for clock skew
+	Profile *model.DeviceProfile
+	Clock   *model.ClockSkew // learned device clock offset, nil if unknown
+
+	// Response being assembled; rules may fill estimate and correction fields
+	Response *model.CoordinateResponse
+}
+
+// RuleOutcome is the verdict of a single rule.
+type RuleOutcome struct {
+	Checks []model.CheckResult
+
+	// Fraction of the running confidence removed by the rule, 0 = no effect
+	Penalty float32
+
+	// Failed stops the chain with INVALID if the rule short-circuits
+	Failed bool
+	Reason string
+}
+
+// RuleFactory builds a rule bound to a validation core.
+type RuleFactory func(v *ValidationCore) Rule
+
+// Built-in rule names
+const (
+	RuleTime          = "time"
+	RuleGeofence      = "geofence"
+	RuleCountry       = "country"
+	RuleSpeed         = "speed"
+	RuleGNSS          = "gnss"
+	RuleSpoofing      = "spoofing"
+	RuleTriangulation = "triangulation"
+	RuleTrack         = "track"
+)
+
+var (
+	rulesMu       sync.RWMutex
+	ruleFactories = map[string]RuleFactory{
+		RuleTime:          func(v *ValidationCore) Rule { return &timeRule{v} },
+		RuleGeofence:      func(v *ValidationCore) Rule { return &geofenceRule{v} },
+		RuleCountry:       func(v *ValidationCore) Rule { return &countryRule{v} },
+		RuleSpeed:         func(v *ValidationCore) Rule { return &speedRule{v} },
+		RuleGNSS:          func(v *ValidationCore) Rule { return &gnssRule{v} },
+		RuleSpoofing:      func(v *ValidationCore) Rule { return &spoofingRule{v} },
+		RuleTriangulation: func(v *ValidationCore) Rule { return &triangulationRule{v} },
+		RuleTrack:         func(v *ValidationCore) Rule { return &trackRule{v} },
+	}
+)
+
+// RegisterRule makes a custom rule available to the VALIDATION_RULES list.
+// It must be called before NewValidationCore, typically from an init function.
+func RegisterRule(name string, factory RuleFactory) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	ruleFactories[name] = factory
+}
+
+type chainedRule struct {
+	rule Rule
+	cfg  config.RuleConfig
+}
+
+// buildChain instantiates the enabled rules in configured order.
+func (v *ValidationCore) buildChain() []chainedRule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	var chain []chainedRule
+	for _, rc := range v.cfg.Rules {
+		if !rc.Enabled {
+			continue
+		}
+		factory, ok := ruleFactories[rc.Name]
+		if !ok {
+			log.Printf("Warning: unknown validation rule %q, skipping", rc.Name)
+			continue
+		}
+		chain = append(chain, chainedRule{rule: factory(v), cfg: rc})
+	}
+	return chain
+}
+
+// RuleNames returns the names of the active rules in evaluation order.
+func (v *ValidationCore) RuleNames() []string {
+	names := make([]string, len(v.rules))
+	for i, r := range v.rules {
+		names[i] = r.rule.Name()
+	}
+	return names
+}
+
+// multiplier converts a rule penalty into a confidence factor scaled by the
+// rule weight: weight 0 mutes the rule, weight 2 doubles its effect in log space.
+func (r chainedRule) multiplier(penalty float32) float32 {
+	f := 1 - math.Min(math.Max(float64(penalty), 0), 1)
+	if r.cfg.Weight != 1 {
+		f = math.Pow(f, math.Max(r.cfg.Weight, 0))
+	}
+	return float32(f)
+}
+
+// ============================================
+// Built-in Rules
+// ============================================
+
+// timeRule rejects future and stale timestamps.
+type timeRule struct{ v *ValidationCore }
+
+func (r *timeRule) Name() string { return RuleTime }
+
+func (r *timeRule) Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error) {
+	check, err := r.v.validateTime(ctx, rc.Request, rc.Profile)
+	out := RuleOutcome{Checks: []model.CheckResult{check}}
+	if err != nil {
+		out.Failed = true
+		out.Penalty = 0.9
+		out.Reason = err.Error()
+	}
+	if clock, ok := r.v.clockCheck(rc.Clock); ok {
+		out.Checks = append(out.Checks, clock)
+	}
+	return out, nil
+}
+
+// geofenceRule applies forbidden and expected zones.
+type geofenceRule struct{ v *ValidationCore }
+
+func (r *geofenceRule) Name() string { return RuleGeofence }
+
+func (r *geofenceRule) Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error) {
+	req := rc.Request
+	checks, multiplier := r.v.geofences.Evaluate(ctx, req.DeviceID, req.Latitude, req.Longitude)
+	return failedChecksOutcome(checks, multiplier), nil
+}
+
+// countryRule compares the position's country with the cells' MCC countries.
+type countryRule struct{ v *ValidationCore }
+
+func (r *countryRule) Name() string { return RuleCountry }
+
+func (r *countryRule) Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error) {
+	req := rc.Request
+	checks, multiplier := r.v.countries.Evaluate(req.CellTowers, req.Latitude, req.Longitude)
+	return failedChecksOutcome(checks, multiplier), nil
+}
+
+// failedChecksOutcome builds the outcome of a rule that fails if any of its
+// checks failed.
+func failedChecksOutcome(checks []model.CheckResult, multiplier float64) RuleOutcome {
+	out := RuleOutcome{
+		Checks:  checks,
+		Penalty: float32(1 - multiplier),
+	}
+	var reasons []string
+	for _, c := range checks {
+		if !c.Passed {
+			reasons = append(reasons, c.Message)
+		}
+	}
+	if len(reasons) > 0 {
+		out.Failed = true
+		out.Reason = strings.Join(reasons, "; ")
+	}
+	return out
+}
+
+// speedRule checks speed, acceleration and heading against the track window,
+// and the reappearance region after a reporting gap. Reappearing outside the
+// region only lowers confidence.
+type speedRule struct{ v *ValidationCore }
+
+func (r *speedRule) Name() string { return RuleSpeed }
+
+func (r *speedRule) Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error) {
+	res, err := r.v.validateSpeed(ctx, rc.Request, rc.Profile)
+	if err != nil {
+		return RuleOutcome{}, err
+	}
+	out := RuleOutcome{
+		Checks:  []model.CheckResult{res.toCheck(0)},
+		Penalty: 1 - res.penalty(),
+		Failed:  !res.valid,
+		Reason:  res.reason,
+	}
+	if g := res.gap; g != nil {
+		out.Checks = append(out.Checks, g.toCheck(0))
+		out.Penalty = 1 - res.penalty()*g.penalty()
+	}
+	return out, nil
+}
+
+// gnssRule scores the receiver metadata reported with the fix.
+type gnssRule struct{ v *ValidationCore }
+
+func (r *gnssRule) Name() string { return RuleGNSS }
+
+func (r *gnssRule) Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error) {
+	checks, multiplier := r.v.checkGNSS(ctx, rc.Request)
+	return failedChecksOutcome(checks, multiplier), nil
+}
+
+// spoofingRule scores the track for synthetic patterns and alerts on
+// likely spoofing.
+type spoofingRule struct{ v *ValidationCore }
+
+func (r *spoofingRule) Name() string { return RuleSpoofing }
+
+func (r *spoofingRule) Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error) {
+	res, err := r.v.detectSpoofing(ctx, rc.Request)
+	if err != nil {
+		log.Printf("Warning: spoofing check skipped for %s: %v", rc.Request.DeviceID, err)
+		return RuleOutcome{}, nil
+	}
+	rc.Response.SpoofingScore = float32(res.score)
+	r.v.alertSpoofing(ctx, rc.Request, res)
+	return failedChecksOutcome(res.checks, 1-res.score), nil
+}
+
+// triangulationRule matches the reported fix against cached sources.
+type triangulationRule struct{ v *ValidationCore }
+
+func (r *triangulationRule) Name() string { return RuleTriangulation }
+
+func (r *triangulationRule) Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error) {
+	tri := r.v.triangulate(ctx, rc.Request)
+	rc.Response.EstimatedAccuracy = tri.estimatedAccuracy
+	rc.Response.Estimate = tri.estimate
+
+	out := RuleOutcome{
+		Checks:  tri.checks,
+		Penalty: 1 - tri.confidence,
+	}
+	var reasons []string
+	for _, c := range tri.checks {
+		if (c.Check == model.CheckSourceDistance || c.Check == model.CheckWifiFingerprint) && !c.Passed {
+			reasons = append(reasons, c.Message)
+		}
+	}
+	if len(reasons) > 0 {
+		out.Failed = true
+		out.Reason = strings.Join(reasons, "; ")
+	}
+	return out, nil
+}
+
+// trackRule compares the fix with the Kalman-smoothed device track.
+type trackRule struct{ v *ValidationCore }
+
+func (r *trackRule) Name() string { return RuleTrack }
+
+func (r *trackRule) Evaluate(ctx context.Context, rc *RuleContext) (RuleOutcome, error) {
+	req := rc.Request
+	gate := r.v.cfg.Kalman.InnovationGate
+
+	// Falls back to the raw fix if state is unavailable
+	smoothed, err := r.v.smooth(ctx, req)
+	if err != nil {
+		smoothed = kalmanResult{latitude: req.Latitude, longitude: req.Longitude}
+	}
+	rc.Response.CorrectedLatitude = smoothed.latitude
+	rc.Response.CorrectedLongitude = smoothed.longitude
+	rc.Response.AnomalyScore = float32(smoothed.anomalyScore())
+
+	check := model.CheckResult{
+		Check:     model.CheckTrack,
+		Code:      model.CheckCodeOK,
+		Passed:    true,
+		Value:     smoothed.nis,
+		Threshold: gate,
+	}
+	out := RuleOutcome{}
+	if smoothed.nis > gate {
+		check.Code = model.CheckCodeTrackDeviation
+		check.Passed = false
+		check.Message = "Position deviates from smoothed track"
+		out.Penalty = float32(1 - math.Max(0.25, gate/smoothed.nis))
+		out.Failed = true
+		out.Reason = check.Message
+	}
+	out.Checks = []model.CheckResult{check}
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Pre-validation: Coordinate Sanity
+// ============================================
+
+// CheckCoordinateSanity rejects fixes that can't be real positions: NaN/Inf,
+// out-of-range latitude or longitude, exact (0,0), non-positive accuracy and
+// coordinates truncated to a few decimals. It touches no state, so callers
+// run it before any cache access.
+func CheckCoordinateSanity(cfg *config.SanityConfig, lat, lon float64, accuracy float32) (model.CheckResult, error) {
+	acc := float64(accuracy)
+
+	var err *ValidationError
+	var value, threshold float64
+	switch {
+	case math.IsNaN(lat) || math.IsInf(lat, 0) ||
+		math.IsNaN(lon) || math.IsInf(lon, 0) ||
+		math.IsNaN(acc) || math.IsInf(acc, 0):
+		err = &ValidationError{
+			Code:    model.CheckCodeInvalidNumber,
+			Message: "Coordinates or accuracy are not finite numbers",
+		}
+	case lat < -90 || lat > 90:
+		err = &ValidationError{
+			Code:    model.CheckCodeLatitudeOutOfRange,
+			Message: fmt.Sprintf("Latitude %f is outside [-90, 90]", lat),
+		}
+		value, threshold = lat, 90
+	case lon < -180 || lon > 180:
+		err = &ValidationError{
+			Code:    model.CheckCodeLongitudeOutOfRange,
+			Message: fmt.Sprintf("Longitude %f is outside [-180, 180]", lon),
+		}
+		value, threshold = lon, 180
+	case lat == 0 && lon == 0:
+		err = &ValidationError{
+			Code:    model.CheckCodeNullIsland,
+			Message: "Coordinates are exactly (0, 0)",
+		}
+	case acc <= 0:
+		err = &ValidationError{
+			Code:    model.CheckCodeInvalidAccuracy,
+			Message: fmt.Sprintf("Accuracy %.1f m must be positive", acc),
+		}
+		value = acc
+	default:
+		minDecimals := cfg.MinCoordinateDecimals
+		d := decimalPlaces(lat)
+		if dLon := decimalPlaces(lon); dLon > d {
+			d = dLon
+		}
+		if minDecimals > 0 && d < minDecimals {
+			err = &ValidationError{
+				Code:    model.CheckCodePrecisionArtifact,
+				Message: fmt.Sprintf("Coordinates have only %d decimal places (minimum %d)", d, minDecimals),
+			}
+			value, threshold = float64(d), float64(minDecimals)
+		}
+	}
+
+	if err != nil {
+		return err.toCheck(model.CheckSanity, value, threshold), err
+	}
+	return model.CheckResult{
+		Check:  model.CheckSanity,
+		Code:   model.CheckCodeOK,
+		Passed: true,
+	}, nil
+}
+
+// decimalPlaces returns the number of decimals in the shortest representation of f.
+func decimalPlaces(f float64) int {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
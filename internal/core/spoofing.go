@@ -0,0 +1,265 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Spoofing Patterns
+// ============================================
+//
+// Real receivers jitter: speed, heading, accuracy and the low decimals of
+// the coordinates all vary from fix to fix. Spoofing apps replay synthetic
+// tracks that don't. Each pattern found raises the spoofing score.
+
+// Score contribution of each pattern; the score is 1 - Π(1 - weight)
+var spoofingWeights = map[model.CheckCode]float64{
+	model.CheckCodeConstantMotion:    0.5,
+	model.CheckCodeFrozenCoordinates: 0.5,
+	model.CheckCodeConstantAccuracy:  0.25,
+	model.CheckCodeGridSnapping:      0.35,
+}
+
+// Segments slower than this (m/s) don't count as motion
+const spoofingMinSpeedMS = 1.0
+
+// AlertPublisher receives alerts about suspicious devices;
+// *queue.KafkaProducer implements it.
+type AlertPublisher interface {
+	SendAlert(ctx context.Context, event *model.AlertEvent) error
+}
+
+// SetAlertPublisher enables alerts. Without a publisher scores are only
+// reported in responses.
+func (v *ValidationCore) SetAlertPublisher(p AlertPublisher) {
+	v.alerts = p
+}
+
+type spoofingResult struct {
+	score  float64
+	checks []model.CheckResult
+}
+
+// detectSpoofing looks for synthetic track patterns in the fix and the
+// device history before it.
+func (v *ValidationCore) detectSpoofing(ctx context.Context, req *model.CoordinateRequest) (spoofingResult, error) {
+	cfg := &v.cfg.Spoofing
+
+	history, err := v.cache.GetTrackBefore(ctx, req.DeviceID, req.Timestamp, cfg.WindowSize)
+	if err != nil {
+		return spoofingResult{}, err
+	}
+
+	// Newest first, the fix itself included
+	points := make([]model.TrackPoint, 0, len(history)+1)
+	points = append(points, model.TrackPoint{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Accuracy:  req.Accuracy,
+		Timestamp: req.Timestamp,
+	})
+	points = append(points, history...)
+
+	var res spoofingResult
+	found := func(code model.CheckCode, value, threshold float64, msg string) {
+		res.checks = append(res.checks, model.CheckResult{
+			Check:     model.CheckSpoofing,
+			Code:      code,
+			Value:     value,
+			Threshold: threshold,
+			Message:   msg,
+		})
+	}
+
+	// A parked tracker repeats its last fix too; frozen coordinates only
+	// count while the receiver claims motion or the device moved in between
+	repeats, interleaved := frozenRepeats(points)
+	if repeats >= cfg.MinRepeats && (interleaved || v.claimsMotion(req)) {
+		found(model.CheckCodeFrozenCoordinates, float64(repeats), float64(cfg.MinRepeats),
+			fmt.Sprintf("Identical coordinates reported %d times with different timestamps", repeats))
+	}
+
+	if len(points) >= cfg.MinPoints {
+		if cv, headingStd, ok := motionVariation(points); ok && cv < cfg.MaxSpeedCV && headingStd < cfg.MaxHeadingStdDeg {
+			found(model.CheckCodeConstantMotion, cv, cfg.MaxSpeedCV,
+				fmt.Sprintf("Speed and heading constant over %d fixes (speed CV %.4f, heading deviation %.2f°)",
+					len(points), cv, headingStd))
+		}
+		if constantAccuracy(points) {
+			found(model.CheckCodeConstantAccuracy, float64(req.Accuracy), 0,
+				fmt.Sprintf("Accuracy identical (%.1f m) over %d fixes", req.Accuracy, len(points)))
+		}
+		if step, ok := gridStep(points, cfg.MinPoints-1); ok && step >= cfg.GridMinStepDeg {
+			found(model.CheckCodeGridSnapping, step, cfg.GridMinStepDeg,
+				fmt.Sprintf("Coordinates change in multiples of %g° over %d fixes", step, len(points)))
+		}
+	}
+
+	keep := 1.0
+	for _, c := range res.checks {
+		keep *= 1 - spoofingWeights[c.Code]
+	}
+	res.score = 1 - keep
+
+	if len(res.checks) == 0 {
+		res.checks = append(res.checks, model.CheckResult{
+			Check:  model.CheckSpoofing,
+			Code:   model.CheckCodeOK,
+			Passed: true,
+		})
+	}
+	return res, nil
+}
+
+// alertSpoofing publishes an alert when the score crosses the threshold,
+// at most once per cooldown per device.
+func (v *ValidationCore) alertSpoofing(ctx context.Context, req *model.CoordinateRequest, res spoofingResult) {
+	cfg := &v.cfg.Spoofing
+	if v.alerts == nil || res.score < cfg.AlertThreshold {
+		return
+	}
+
+	first, err := v.cache.MarkAlert(ctx, string(model.AlertTypeSpoofing), req.DeviceID, cfg.AlertCooldown)
+	if err != nil {
+		log.Printf("Warning: failed to record spoofing alert for %s: %v", req.DeviceID, err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	reasons := make([]model.CheckCode, 0, len(res.checks))
+	for _, c := range res.checks {
+		reasons = append(reasons, c.Code)
+	}
+	err = v.alerts.SendAlert(ctx, &model.AlertEvent{
+		Type:      model.AlertTypeSpoofing,
+		DeviceID:  req.DeviceID,
+		Score:     float32(res.score),
+		Reasons:   reasons,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Timestamp: req.Timestamp,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to send spoofing alert for %s: %v", req.DeviceID, err)
+	}
+}
+
+// frozenRepeats counts points at exactly the newest point's coordinates
+// with distinct timestamps, and reports whether other positions lie between
+// them.
+func frozenRepeats(points []model.TrackPoint) (int, bool) {
+	first := points[0]
+	stamps := make(map[int64]bool)
+	moved, interleaved := false, false
+	for _, p := range points {
+		if p.Latitude == first.Latitude && p.Longitude == first.Longitude {
+			stamps[p.Timestamp] = true
+			interleaved = interleaved || moved
+		} else {
+			moved = true
+		}
+	}
+	return len(stamps), interleaved
+}
+
+// claimsMotion reports whether the receiver reports a speed at which the
+// position must change from fix to fix.
+func (v *ValidationCore) claimsMotion(req *model.CoordinateRequest) bool {
+	g := req.GNSS
+	return g != nil && g.SpeedKmH != nil && *g.SpeedKmH >= v.cfg.Trajectory.MinHeadingSpeedKmH
+}
+
+// motionVariation returns the coefficient of variation of segment speeds
+// and the RMS heading change between segments. ok is false unless every
+// segment is moving.
+func motionVariation(points []model.TrackPoint) (cv, headingStd float64, ok bool) {
+	var speeds, bearings []float64
+	for i := len(points) - 1; i > 0; i-- {
+		a, b := points[i], points[i-1]
+		dt := float64(b.Timestamp - a.Timestamp)
+		if dt <= 0 {
+			return 0, 0, false
+		}
+		speed := HaversineDistance(a.Latitude, a.Longitude, b.Latitude, b.Longitude) * 1000 / dt
+		if speed < spoofingMinSpeedMS {
+			return 0, 0, false
+		}
+		speeds = append(speeds, speed)
+		bearings = append(bearings, Bearing(a.Latitude, a.Longitude, b.Latitude, b.Longitude))
+	}
+	if len(speeds) < 2 {
+		return 0, 0, false
+	}
+
+	var mean, sq float64
+	for _, s := range speeds {
+		mean += s
+	}
+	mean /= float64(len(speeds))
+	for _, s := range speeds {
+		sq += (s - mean) * (s - mean)
+	}
+	cv = math.Sqrt(sq/float64(len(speeds))) / mean
+
+	var turn float64
+	for i := 1; i < len(bearings); i++ {
+		d := headingDiff(bearings[i], bearings[i-1])
+		turn += d * d
+	}
+	headingStd = math.Sqrt(turn / float64(len(bearings)-1))
+	return cv, headingStd, true
+}
+
+func constantAccuracy(points []model.TrackPoint) bool {
+	for _, p := range points[1:] {
+		if p.Accuracy != points[0].Accuracy {
+			return false
+		}
+	}
+	return points[0].Accuracy > 0
+}
+
+// Resolution (1/degrees) at which coordinate changes are compared; finer
+// than any receiver reports
+const gridResolution = 1e7
+
+// gridStep returns the largest step (degrees) that every change of latitude
+// and longitude between consecutive points is a multiple of. Snapped or
+// stepped coordinates share a coarse step whatever their trailing digits;
+// real fixes only share the receiver's resolution. ok is false with fewer
+// than minChanges non-zero changes.
+func gridStep(points []model.TrackPoint, minChanges int) (step float64, ok bool) {
+	var g int64
+	changes := 0
+	for i := 1; i < len(points); i++ {
+		for _, d := range []float64{
+			points[i].Latitude - points[i-1].Latitude,
+			points[i].Longitude - points[i-1].Longitude,
+		} {
+			n := int64(math.Abs(math.Round(d * gridResolution)))
+			if n == 0 {
+				continue
+			}
+			changes++
+			g = gcd(g, n)
+		}
+	}
+	if changes < minChanges || changes == 0 {
+		return 0, false
+	}
+	return float64(g) / gridResolution, true
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"coordinate-validator/internal/model"
+)
+
+func TestGridStep(t *testing.T) {
+	track := func(coords ...[2]float64) []model.TrackPoint {
+		points := make([]model.TrackPoint, len(coords))
+		for i, c := range coords {
+			points[i] = model.TrackPoint{Latitude: c[0], Longitude: c[1]}
+		}
+		return points
+	}
+
+	tests := []struct {
+		name   string
+		points []model.TrackPoint
+		step   float64
+		ok     bool
+	}{
+		{
+			name:   "receiver jitter",
+			points: track([2]float64{55.7512441, 37.6184233}, [2]float64{55.7513127, 37.6185902}, [2]float64{55.7514386, 37.6187155}),
+			step:   0.0000001,
+			ok:     true,
+		},
+		{
+			name:   "snapped to 4 decimals",
+			points: track([2]float64{55.7512, 37.6184}, [2]float64{55.7514, 37.6187}, [2]float64{55.7515, 37.6191}),
+			step:   0.0001,
+			ok:     true,
+		},
+		{
+			name:   "constant trailing digits",
+			points: track([2]float64{55.751237, 37.618437}, [2]float64{55.751537, 37.618937}, [2]float64{55.752137, 37.619437}),
+			step:   0.0001,
+			ok:     true,
+		},
+		{
+			name:   "constant increments",
+			points: track([2]float64{55.7512441, 37.6184233}, [2]float64{55.7514941, 37.6186733}, [2]float64{55.7517441, 37.6189233}),
+			step:   0.00025,
+			ok:     true,
+		},
+		{
+			name:   "too few changes",
+			points: track([2]float64{55.7512, 37.6184}, [2]float64{55.7512, 37.6184}, [2]float64{55.7513, 37.6184}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := gridStep(tt.points, 3)
+			if ok != tt.ok || math.Abs(step-tt.step) > 1e-12 {
+				t.Errorf("gridStep = %g (%v), want %g (%v)", step, ok, tt.step, tt.ok)
+			}
+		})
+	}
+}
+
+func TestValidateGridSnapping(t *testing.T) {
+	v, _ := newTestCore(t)
+	ctx := context.Background()
+	t0 := time.Now().Unix() - 3600
+
+	// Roughly 40 km/h north-east with varying speed and heading
+	moves := [][2]float64{{0, 0}, {650, 120}, {1240, 310}, {1900, 380}, {2480, 600}, {3150, 690}, {3700, 900}}
+	snap := func(req model.CoordinateRequest) model.CoordinateRequest {
+		req.Latitude = math.Round(req.Latitude*1e4) / 1e4
+		req.Longitude = math.Round(req.Longitude*1e4) / 1e4
+		return req
+	}
+
+	tests := []struct {
+		name    string
+		snapped bool
+	}{
+		{"receiver fixes", false},
+		{"snapped to 4 decimals", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fixes []model.CoordinateRequest
+			for i, m := range moves {
+				fix := fixAt(tt.name, m[0], m[1], t0+int64(i)*60)
+				fix.Accuracy = float32(8 + i%3)
+				if tt.snapped {
+					fix = snap(fix)
+				}
+				fixes = append(fixes, fix)
+			}
+			seedTrack(t, v, fixes[:len(fixes)-1]...)
+
+			resp, err := v.Validate(ctx, &fixes[len(fixes)-1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			snapping := false
+			for _, c := range resp.Checks {
+				if c.Check == model.CheckSpoofing && c.Code == model.CheckCodeGridSnapping {
+					snapping = true
+				}
+			}
+			if snapping != tt.snapped {
+				t.Errorf("grid snapping = %v, want %v (checks %+v)", snapping, tt.snapped, resp.Checks)
+			}
+			if tt.snapped && resp.SpoofingScore <= 0 {
+				t.Errorf("spoofing score = %v, want it raised", resp.SpoofingScore)
+			}
+		})
+	}
+}
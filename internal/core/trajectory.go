@@ -0,0 +1,121 @@
+package core
+
+import (
+	"math"
+	"sort"
+
+	"coordinate-validator/internal/model"
+)
+
+// ============================================
+// Trajectory Window Helpers
+// ============================================
+
+// plausibleDistance returns the minimum distance in km between two fixes
+// consistent with their accuracy radii (meters), floored at zero.
+func plausibleDistance(lat1, lon1 float64, acc1 float32, lat2, lon2 float64, acc2 float32) float64 {
+	d := HaversineDistance(lat1, lon1, lat2, lon2) -
+		(math.Max(float64(acc1), 0)+math.Max(float64(acc2), 0))/1000
+	return math.Max(d, 0)
+}
+
+// MedianSpeedKmH returns the median of speeds implied between the given
+// point and every earlier point of the window, using plausible distances,
+// 0 if no point is earlier.
+func MedianSpeedKmH(window []model.TrackPoint, lat, lon float64, acc float32, ts int64) float64 {
+	speeds := make([]float64, 0, len(window))
+	for _, p := range window {
+		hours := float64(ts-p.Timestamp) / 3600
+		if hours <= 0 {
+			continue
+		}
+		d := plausibleDistance(p.Latitude, p.Longitude, p.Accuracy, lat, lon, acc)
+		speeds = append(speeds, d/hours)
+	}
+	return median(speeds)
+}
+
+// median returns the median of values, 0 if there are none. values is
+// sorted in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// segmentPair describes two consecutive track segments a -> b -> c.
+type segmentPair struct {
+	prevSpeed   float64 // m/s, a -> b
+	nextSpeed   float64 // m/s, b -> c
+	prevBearing float64 // degrees
+	nextBearing float64 // degrees
+	dt          float64 // seconds between segment midpoints
+}
+
+// newSegmentPair builds segments a -> b -> req. Speeds use plausible
+// distances so GPS noise alone doesn't read as acceleration.
+func newSegmentPair(a, b model.TrackPoint, req *model.CoordinateRequest) segmentPair {
+	dt1 := float64(b.Timestamp - a.Timestamp)
+	dt2 := float64(req.Timestamp - b.Timestamp)
+
+	var seg segmentPair
+	if dt1 > 0 {
+		d := plausibleDistance(a.Latitude, a.Longitude, a.Accuracy, b.Latitude, b.Longitude, b.Accuracy)
+		seg.prevSpeed = d * 1000 / dt1
+	}
+	if dt2 > 0 {
+		d := plausibleDistance(b.Latitude, b.Longitude, b.Accuracy, req.Latitude, req.Longitude, req.Accuracy)
+		seg.nextSpeed = d * 1000 / dt2
+	}
+	seg.prevBearing = Bearing(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	seg.nextBearing = Bearing(b.Latitude, b.Longitude, req.Latitude, req.Longitude)
+	seg.dt = (dt1 + dt2) / 2
+	return seg
+}
+
+// acceleration returns the absolute speed change in m/s².
+func (s segmentPair) acceleration() float64 {
+	if s.dt <= 0 {
+		return 0
+	}
+	return math.Abs(s.nextSpeed-s.prevSpeed) / s.dt
+}
+
+// turnRate returns the heading change in degrees per second.
+func (s segmentPair) turnRate() float64 {
+	if s.dt <= 0 {
+		return 0
+	}
+	return headingDiff(s.prevBearing, s.nextBearing) / s.dt
+}
+
+// ============================================
+// Helper: Bearing (degrees)
+// ============================================
+
+// Bearing returns the initial bearing from point 1 to point 2 in degrees [0, 360).
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	dLon := toRad(lon2 - lon1)
+	y := math.Sin(dLon) * math.Cos(toRad(lat2))
+	x := math.Cos(toRad(lat1))*math.Sin(toRad(lat2)) -
+		math.Sin(toRad(lat1))*math.Cos(toRad(lat2))*math.Cos(dLon)
+
+	deg := math.Atan2(y, x) * 180 / math.Pi
+	return math.Mod(deg+360, 360)
+}
+
+// headingDiff returns the absolute difference between two bearings in [0, 180].
+func headingDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"coordinate-validator/internal/cache"
@@ -11,15 +13,42 @@ import (
 )
 
 type ValidationCore struct {
-	cache *cache.RedisCache
-	cfg   *config.ValidationConfig
+	cache     *cache.RedisCache
+	cfg       *config.ValidationConfig
+	profiles  *ProfileRegistry
+	geofences *GeofenceRegistry
+	corridors *CorridorRegistry
+	countries *CountryRegistry
+	alerts    AlertPublisher
+	rules     []chainedRule
 }
 
 func NewValidationCore(cache *cache.RedisCache, cfg *config.ValidationConfig) *ValidationCore {
-	return &ValidationCore{
-		cache: cache,
-		cfg:   cfg,
+	v := &ValidationCore{
+		cache:     cache,
+		cfg:       cfg,
+		profiles:  NewProfileRegistry(cache, cfg),
+		geofences: NewGeofenceRegistry(cache, &cfg.Geofence),
+		corridors: NewCorridorRegistry(cache, &cfg.Corridor),
+		countries: NewCountryRegistry(&cfg.Country),
 	}
+	v.rules = v.buildChain()
+	return v
+}
+
+// Profiles returns the device profile registry used by the core.
+func (v *ValidationCore) Profiles() *ProfileRegistry {
+	return v.profiles
+}
+
+// Geofences returns the zone registry used by the core.
+func (v *ValidationCore) Geofences() *GeofenceRegistry {
+	return v.geofences
+}
+
+// Corridors returns the transit corridor registry used by the core.
+func (v *ValidationCore) Corridors() *CorridorRegistry {
+	return v.corridors
 }
 
 // ============================================
@@ -27,72 +56,153 @@ func NewValidationCore(cache *cache.RedisCache, cfg *config.ValidationConfig) *V
 // ============================================
 
 func (v *ValidationCore) Validate(ctx context.Context, req *model.CoordinateRequest) (*model.CoordinateResponse, error) {
-	// Layer 1: Rule-based validation
-	if err := v.validateTime(ctx, req); err != nil {
+	// Garbage coordinates never reach the cache
+	sanity, err := CheckCoordinateSanity(&v.cfg.Sanity, req.Latitude, req.Longitude, req.Accuracy)
+	if err != nil {
 		return &model.CoordinateResponse{
-			Result:   model.ValidationResultInvalid,
-			Reason:  err.Error(),
+			Result: model.ValidationResultInvalid,
+			Reason: err.Error(),
+			Checks: []model.CheckResult{sanity},
 		}, nil
 	}
 
-	speedCheck, err := v.validateSpeed(ctx, req)
-	if err != nil {
-		return &model.CoordinateResponse{
-			Result:   model.ValidationResultInvalid,
-			Reason:  err.Error(),
-		}, nil
+	// Learn the device clock and check timestamps in server time; without
+	// clock state timestamps are checked as reported
+	skew, _ := v.observeClock(ctx, req)
+	req = v.correctTimestamp(req, skew)
+
+	// Every fix counts towards clone detection, whatever its verdict
+	v.observeClones(ctx, req)
+
+	rc := &RuleContext{
+		Request: req,
+		Profile: v.profiles.Resolve(ctx, req.DeviceID),
+		Clock:   skew,
+		Response: &model.CoordinateResponse{
+			EstimatedAccuracy:  req.Accuracy,
+			CorrectedLatitude:  req.Latitude,
+			CorrectedLongitude: req.Longitude,
+			Checks:             []model.CheckResult{sanity},
+		},
 	}
+	resp := rc.Response
+
+	var confidence float32 = 1.0
+	for _, r := range v.rules {
+		out, err := r.rule.Evaluate(ctx, rc)
+		if err != nil {
+			resp.Result = model.ValidationResultInvalid
+			resp.Reason = err.Error()
+			return resp, nil
+		}
+
+		if out.Failed && r.cfg.ShortCircuit {
+			resp.Checks = append(resp.Checks, out.Checks...)
+			resp.Result = model.ValidationResultInvalid
+			resp.Confidence = 0
+			resp.Reason = out.Reason
+			if resp.Reason == "" {
+				resp.Reason = summarizeChecks(resp.Checks)
+			}
+			return resp, nil
+		}
 
-	// Layer 2: Triangulation via sources
-	confidence, estimatedAccuracy, reasons := v.triangulate(ctx, req)
+		before := confidence
+		confidence *= r.multiplier(out.Penalty)
 
-	// Apply speed check result
-	if !speedCheck.valid {
-		confidence *= 0.5
-		reasons = append(reasons, speedCheck.reason)
+		// Unless the rule attributed contributions itself, its effect on
+		// confidence is reported on its primary check
+		if len(out.Checks) > 0 && !hasContribution(out.Checks) {
+			out.Checks[0].Contribution = confidence - before
+		}
+		resp.Checks = append(resp.Checks, out.Checks...)
 	}
 
-	// Determine final result
-	result := v.determineResult(confidence)
-	reason := ""
-	if len(reasons) > 0 {
-		reason = reasons[0]
+	resp.Result = v.determineResult(confidence)
+	resp.Confidence = confidence
+	resp.Reason = summarizeChecks(resp.Checks)
+	return resp, nil
+}
+
+func hasContribution(checks []model.CheckResult) bool {
+	for _, c := range checks {
+		if c.Contribution != 0 {
+			return true
+		}
 	}
+	return false
+}
 
-	return &model.CoordinateResponse{
-		Result:             result,
-		Confidence:         confidence,
-		EstimatedAccuracy: estimatedAccuracy,
-		Reason:             reason,
-	}, nil
+// summarizeChecks joins the messages of failed checks, then of passed ones,
+// into a single human-readable reason.
+func summarizeChecks(checks []model.CheckResult) string {
+	var failed, passed []string
+	for _, c := range checks {
+		if c.Message == "" {
+			continue
+		}
+		if c.Passed {
+			passed = append(passed, c.Message)
+		} else {
+			failed = append(failed, c.Message)
+		}
+	}
+	return strings.Join(append(failed, passed...), "; ")
+}
+
+// smooth runs the device track filter for req without persisting it.
+// Late points don't move the filter and are returned uncorrected.
+func (v *ValidationCore) smooth(ctx context.Context, req *model.CoordinateRequest) (kalmanResult, error) {
+	pos, err := v.cache.GetDevicePosition(ctx, req.DeviceID)
+	if err != nil {
+		return kalmanResult{}, err
+	}
+
+	var prev *model.KalmanState
+	if pos != nil {
+		prev = pos.Kalman
+	}
+	if prev != nil && req.Timestamp < prev.Timestamp {
+		return kalmanResult{latitude: req.Latitude, longitude: req.Longitude}, nil
+	}
+	return v.kalmanStep(prev, req), nil
 }
 
 // ============================================
 // Layer 1: Time Validation
 // ============================================
 
-func (v *ValidationCore) validateTime(ctx context.Context, req *model.CoordinateRequest) error {
+func (v *ValidationCore) validateTime(ctx context.Context, req *model.CoordinateRequest, profile *model.DeviceProfile) (model.CheckResult, error) {
 	now := time.Now().Unix()
 	reqTime := req.Timestamp
+	age := float64(now - reqTime)
 
-	// Check if timestamp is in the future
-	if reqTime > now {
-		return &ValidationError{
-			Code:    "FUTURE_TIMESTAMP",
+	// Check if timestamp is in the future (after clock correction)
+	if reqTime > now+int64(v.cfg.Clock.FutureTolerance.Seconds()) {
+		err := &ValidationError{
+			Code:    model.CheckCodeFutureTimestamp,
 			Message: "Timestamp is in the future",
 		}
+		return err.toCheck(model.CheckTime, age, 0), err
 	}
 
 	// Check if timestamp is too old
 	diff := time.Duration(now-reqTime) * time.Second
-	if diff > v.cfg.MaxTimeDiff {
-		return &ValidationError{
-			Code:    "TIMESTAMP_TOO_OLD",
+	if diff > profile.MaxTimeDiff {
+		err := &ValidationError{
+			Code:    model.CheckCodeTimestampTooOld,
 			Message: "Timestamp is older than max allowed",
 		}
+		return err.toCheck(model.CheckTime, age, profile.MaxTimeDiff.Seconds()), err
 	}
 
-	return nil
+	return model.CheckResult{
+		Check:     model.CheckTime,
+		Code:      model.CheckCodeOK,
+		Passed:    true,
+		Value:     age,
+		Threshold: profile.MaxTimeDiff.Seconds(),
+	}, nil
 }
 
 // ============================================
@@ -100,89 +210,274 @@ func (v *ValidationCore) validateTime(ctx context.Context, req *model.Coordinate
 // ============================================
 
 type speedCheckResult struct {
-	valid  bool
-	reason string
+	valid     bool
+	reason    string
+	check     model.CheckName
+	code      model.CheckCode
+	value     float64 // measured value
+	threshold float64 // limit
+	subject   string  // corridor that exempted the jump
+
+	gap *speedCheckResult // reappearance check after a reporting gap
+}
+
+func (r speedCheckResult) toCheck(contribution float32) model.CheckResult {
+	check, code := r.check, r.code
+	if check == "" {
+		check = model.CheckSpeed
+	}
+	if code == "" {
+		code = model.CheckCodeOK
+	}
+	return model.CheckResult{
+		Check:        check,
+		Code:         code,
+		Passed:       r.valid,
+		Value:        r.value,
+		Threshold:    r.threshold,
+		Contribution: contribution,
+		Message:      r.reason,
+		Subject:      r.subject,
+	}
 }
 
-func (v *ValidationCore) validateSpeed(ctx context.Context, req *model.CoordinateRequest) (speedCheckResult, error) {
-	// Get last known position for this device
-	lastPos, err := v.cache.GetDevicePosition(ctx, req.DeviceID)
+// penalty returns the confidence multiplier for a failed check, graded by
+// how far the measured value exceeds its threshold: barely over the limit
+// costs 10-20%, twice the limit costs 75%.
+func (r speedCheckResult) penalty() float32 {
+	if r.valid || r.value <= 0 {
+		return 1.0
+	}
+	ratio := r.threshold / r.value
+	p := ratio * ratio
+	if p > 0.9 {
+		p = 0.9
+	}
+	if p < 0.1 {
+		p = 0.1
+	}
+	return float32(p)
+}
+
+func (v *ValidationCore) validateSpeed(ctx context.Context, req *model.CoordinateRequest, profile *model.DeviceProfile) (speedCheckResult, error) {
+	// Late points are placed between their neighbours in the history
+	next, err := v.cache.GetTrackAfter(ctx, req.DeviceID, req.Timestamp, 1)
+	if err != nil {
+		return speedCheckResult{valid: true, reason: ""}, err
+	}
+	if len(next) > 0 {
+		latest, err := v.cache.GetDevicePosition(ctx, req.DeviceID)
+		if err != nil {
+			return speedCheckResult{valid: true, reason: ""}, err
+		}
+		if latest != nil && v.isBeyondReorderWindow(latest.Timestamp, req.Timestamp) {
+			// Too late to place reliably: speed, acceleration and heading
+			// can't vouch for it
+			lag := latest.Timestamp - req.Timestamp
+			return speedCheckResult{
+				valid: false,
+				reason: fmt.Sprintf(
+					"Point is %d s behind the latest one, too late to place in the track (tolerance %.0f s)",
+					lag, v.cfg.Trajectory.ReorderTolerance.Seconds(),
+				),
+				check:     model.CheckSpeed,
+				code:      model.CheckCodeTooLateToPlace,
+				value:     float64(lag),
+				threshold: v.cfg.Trajectory.ReorderTolerance.Seconds(),
+			}, nil
+		}
+
+		// Check speed to the following point
+		seconds := next[0].Timestamp - req.Timestamp
+		distance := plausibleDistance(
+			req.Latitude, req.Longitude, req.Accuracy,
+			next[0].Latitude, next[0].Longitude, next[0].Accuracy,
+		)
+		speed := distance / (float64(seconds) / 3600)
+		if speed > profile.MaxSpeedKmH {
+			if exempt, ok := v.corridorExemption(ctx, req.Latitude, req.Longitude, next[0].Latitude, next[0].Longitude, speed); ok {
+				return exempt, nil
+			}
+			return speedCheckResult{
+				valid: false,
+				reason: fmt.Sprintf(
+					"Speed to following point %.1f km/h exceeds maximum %.1f km/h (min plausible distance %.2f km over %d s)",
+					speed, profile.MaxSpeedKmH, distance, seconds,
+				),
+				check:     model.CheckSpeed,
+				code:      model.CheckCodeNextSpeedExceeded,
+				value:     speed,
+				threshold: profile.MaxSpeedKmH,
+			}, nil
+		}
+	}
+
+	// Get accepted points preceding the request
+	window, err := v.cache.GetTrackBefore(ctx, req.DeviceID, req.Timestamp, v.cfg.Trajectory.WindowSize)
 	if err != nil {
 		return speedCheckResult{valid: true, reason: ""}, err
 	}
 
 	// No previous data - skip check
-	if lastPos == nil {
+	if len(window) == 0 {
 		return speedCheckResult{valid: true, reason: ""}, nil
 	}
 
-	// Calculate time difference
-	timeDiff := time.Duration(req.Timestamp-lastPos.Timestamp) * time.Second
-	if timeDiff <= 0 {
-		return speedCheckResult{valid: true, reason: ""}, nil
+	// Over long reporting gaps the limit is tightened
+	gap := time.Duration(req.Timestamp-window[0].Timestamp) * time.Second
+	limit := v.speedLimitForGap(profile, gap)
+
+	// Median speed over the window so a single bad point can't poison the check
+	speed := MedianSpeedKmH(window, req.Latitude, req.Longitude, req.Accuracy, req.Timestamp)
+	if speed > limit {
+		// Ferries and car trains: the jump from the last fix may follow a corridor
+		if exempt, ok := v.corridorExemption(ctx, window[0].Latitude, window[0].Longitude, req.Latitude, req.Longitude, speed); ok {
+			return exempt, nil
+		}
+		return speedCheckResult{
+			valid: false,
+			reason: fmt.Sprintf(
+				"Speed %.1f km/h exceeds maximum %.1f km/h (median over %d points, accuracy radii subtracted)",
+				speed, limit, len(window),
+			),
+			check:     model.CheckSpeed,
+			code:      model.CheckCodeSpeedExceeded,
+			value:     speed,
+			threshold: limit,
+		}, nil
 	}
+	passed := speedCheckResult{valid: true, value: speed, threshold: limit}
 
-	// Calculate distance
-	distance := HaversineDistance(
-		lastPos.Latitude, lastPos.Longitude,
-		req.Latitude, req.Longitude,
-	)
+	// After a reporting gap acceleration and heading say nothing; check
+	// where the device reappeared instead
+	if v.isGap(gap) {
+		passed.gap = v.checkReappearance(window, req, gap, limit)
+		return passed, nil
+	}
 
-	// Calculate speed in km/h
-	speed := (distance / timeDiff.Hours())
+	// Acceleration and heading need two previous points
+	if len(window) < 2 {
+		return passed, nil
+	}
+
+	seg := newSegmentPair(window[1], window[0], req)
 
-	if speed > v.cfg.MaxSpeedKmH {
+	if accel := seg.acceleration(); accel > profile.MaxAccelerationMS2 {
 		return speedCheckResult{
-			valid:  false,
-			reason: "Speed exceeds maximum",
+			valid: false,
+			reason: fmt.Sprintf(
+				"Acceleration %.1f m/s² exceeds maximum %.1f m/s²",
+				accel, profile.MaxAccelerationMS2,
+			),
+			check:     model.CheckAcceleration,
+			code:      model.CheckCodeAccelerationExceeded,
+			value:     accel,
+			threshold: profile.MaxAccelerationMS2,
 		}, nil
 	}
 
-	return speedCheckResult{valid: true, reason: ""}, nil
+	minSpeed := v.cfg.Trajectory.MinHeadingSpeedKmH
+	if seg.prevSpeed*3.6 >= minSpeed && seg.nextSpeed*3.6 >= minSpeed {
+		if rate := seg.turnRate(); rate > v.cfg.Trajectory.MaxTurnRateDegS {
+			return speedCheckResult{
+				valid: false,
+				reason: fmt.Sprintf(
+					"Heading change %.1f deg/s exceeds maximum %.1f deg/s",
+					rate, v.cfg.Trajectory.MaxTurnRateDegS,
+				),
+				check:     model.CheckHeading,
+				code:      model.CheckCodeHeadingExceeded,
+				value:     rate,
+				threshold: v.cfg.Trajectory.MaxTurnRateDegS,
+			}, nil
+		}
+	}
+
+	return passed, nil
+}
+
+// corridorExemption returns a passed speed check if the jump between the
+// two fixes follows a transit corridor that allows the speed.
+func (v *ValidationCore) corridorExemption(ctx context.Context, lat1, lon1, lat2, lon2, speed float64) (speedCheckResult, bool) {
+	c := v.corridors.Match(ctx, lat1, lon1, lat2, lon2, speed)
+	if c == nil {
+		return speedCheckResult{}, false
+	}
+	name := c.Name
+	if name == "" {
+		name = c.ID
+	}
+	return speedCheckResult{
+		valid:     true,
+		reason:    fmt.Sprintf("Speed %.1f km/h allowed by transit corridor %q", speed, name),
+		check:     model.CheckSpeed,
+		code:      model.CheckCodeCorridorExempt,
+		value:     speed,
+		threshold: c.MaxSpeedKmH,
+		subject:   c.ID,
+	}, true
 }
 
 // ============================================
 // Layer 2: Triangulation
 // ============================================
 
-func (v *ValidationCore) triangulate(ctx context.Context, req *model.CoordinateRequest) (float32, float32, []string) {
-	var reasons []string
+type triangulationResult struct {
+	confidence        float32
+	estimatedAccuracy float32
+	estimate          *model.PositionEstimate
+	checks            []model.CheckResult
+}
+
+// Triangulation confidence when no source is known
+const noSourceConfidence = 0.3
+
+func (v *ValidationCore) triangulate(ctx context.Context, req *model.CoordinateRequest) triangulationResult {
 	var totalConfidence float32 = 0.0
 	var weight float32 = 0.0
+	var fixes []sourceFix
+
+	type sourceMatch struct {
+		check  model.CheckName
+		conf   float32
+		weight float32
+		reason string
+	}
+	var matches []sourceMatch
 
-	// Check WiFi
+	// Check WiFi, individually and as a fingerprint
+	var fingerprint *model.CheckResult
+	fingerprintMultiplier := 1.0
 	if len(req.Wifi) > 0 {
-		conf, acc, r := v.checkWifi(ctx, req.Wifi)
+		conf, f, r := v.checkWifi(ctx, req.Wifi)
+		matches = append(matches, sourceMatch{model.CheckWifi, conf, 0.4, r})
 		if conf > 0 {
 			totalConfidence += conf * 0.4
 			weight += 0.4
-			if r != "" {
-				reasons = append(reasons, r)
-			}
+			fixes = append(fixes, f...)
 		}
+		fingerprint, fingerprintMultiplier = v.matchFingerprint(ctx, req)
 	}
 
 	// Check Cell Towers
 	if len(req.CellTowers) > 0 {
-		conf, acc, r := v.checkCellTowers(ctx, req.CellTowers)
+		conf, f, r := v.checkCellTowers(ctx, req.CellTowers)
+		matches = append(matches, sourceMatch{model.CheckCell, conf, 0.35, r})
 		if conf > 0 {
 			totalConfidence += conf * 0.35
 			weight += 0.35
-			if r != "" {
-				reasons = append(reasons, r)
-			}
+			fixes = append(fixes, f...)
 		}
 	}
 
 	// Check Bluetooth
 	if len(req.Bluetooth) > 0 {
-		conf, acc, r := v.checkBluetooth(ctx, req.Bluetooth)
+		conf, f, r := v.checkBluetooth(ctx, req.Bluetooth)
+		matches = append(matches, sourceMatch{model.CheckBLE, conf, 0.25, r})
 		if conf > 0 {
 			totalConfidence += conf * 0.25
 			weight += 0.25
-			if r != "" {
-				reasons = append(reasons, r)
-			}
+			fixes = append(fixes, f...)
 		}
 	}
 
@@ -191,23 +486,89 @@ func (v *ValidationCore) triangulate(ctx context.Context, req *model.CoordinateR
 		totalConfidence /= weight
 	} else {
 		// No sources available - use base confidence
-		totalConfidence = 0.3
-		weight = 1.0
+		totalConfidence = noSourceConfidence
 	}
 
-	// Estimated accuracy based on available sources
-	estimatedAccuracy := float32(req.Accuracy) * (1.0 - totalConfidence*0.5)
+	// Each matched source type is credited with the change it makes to the
+	// normalized confidence, adding the types in order to the base confidence
+	var checks []model.CheckResult
+	var running, runningWeight float32
+	current := float32(noSourceConfidence)
+	for _, m := range matches {
+		c := model.CheckResult{
+			Check:  m.check,
+			Code:   model.CheckCodeSourceUnknown,
+			Passed: false,
+		}
+		if m.conf > 0 {
+			running += m.conf * m.weight
+			runningWeight += m.weight
+			next := running / runningWeight
+			c.Code = model.CheckCodeSourceMatched
+			c.Passed = true
+			c.Value = float64(m.conf)
+			c.Contribution = next - current
+			c.Message = m.reason
+			current = next
+		}
+		checks = append(checks, c)
+	}
 
-	return totalConfidence, estimatedAccuracy, reasons
+	// A scan that doesn't fit the learned fingerprints lowers confidence
+	if fingerprint != nil {
+		before := totalConfidence
+		totalConfidence *= float32(fingerprintMultiplier)
+		fingerprint.Contribution = totalConfidence - before
+		checks = append(checks, *fingerprint)
+	}
+
+	// The reported accuracy, tightened by an agreeing source estimate
+	estimatedAccuracy := req.Accuracy
+
+	// Compare the reported fix with the position implied by the sources
+	estimate := estimatePosition(fixes, v.cfg.Positioning.MinSources)
+	if estimate != nil {
+		distance := HaversineDistance(req.Latitude, req.Longitude, estimate.Latitude, estimate.Longitude) * 1000
+		penalty := estimatePenalty(distance, estimate.Radius, req.Accuracy)
+		c := model.CheckResult{
+			Check:     model.CheckSourceDistance,
+			Code:      model.CheckCodeOK,
+			Passed:    true,
+			Value:     distance,
+			Threshold: estimate.Radius + math.Max(float64(req.Accuracy), 0),
+		}
+		if penalty < 1.0 {
+			before := totalConfidence
+			totalConfidence *= float32(penalty)
+			c.Code = model.CheckCodeOutsideSourceEstimate
+			c.Passed = false
+			c.Contribution = totalConfidence - before
+			c.Message = fmt.Sprintf(
+				"Reported position is %.0f m from source estimate (radius %.0f m)",
+				distance, estimate.Radius,
+			)
+		} else {
+			estimatedAccuracy = fuseAccuracy(req.Accuracy, estimate.Radius)
+		}
+		checks = append(checks, c)
+	}
+
+	return triangulationResult{
+		confidence:        totalConfidence,
+		estimatedAccuracy: estimatedAccuracy,
+		estimate:          estimate,
+		checks:            checks,
+	}
 }
 
-func (v *ValidationCore) checkWifi(ctx context.Context, wifi []model.WifiAP) (float32, float32, string) {
+func (v *ValidationCore) checkWifi(ctx context.Context, wifi []model.WifiAP) (float32, []sourceFix, string) {
 	var maxConf float32 = 0
-	var avgAccuracy float32 = 0
+	var fixes []sourceFix
 
 	for _, w := range wifi {
 		cached, err := v.cache.GetWifi(ctx, w.BSSID)
-		if err != nil || cached == nil {
+		// Mobile and possibly relocated sources never vouch for a position
+		if err != nil || cached == nil || cached.Mobility == model.SourceMobilityMobile || cached.Quarantined {
 			continue
 		}
 
@@ -216,24 +577,25 @@ func (v *ValidationCore) checkWifi(ctx context.Context, wifi []model.WifiAP) (fl
 		if conf > maxConf {
 			maxConf = conf
 		}
-		avgAccuracy += float32(cached.Confidence * 10) // approximate
+		rng := wifiRange(&v.cfg.Positioning, w, cached.CoverageRadius)
+		fixes = append(fixes, newSourceFix(model.PointTypeWifi, cached.Latitude, cached.Longitude, rng, cached.Confidence))
 	}
 
 	if maxConf > 0 {
-		avgAccuracy /= float32(len(wifi))
-		return maxConf, avgAccuracy, "WiFi triangulation matched"
+		return maxConf, fixes, "WiFi triangulation matched"
 	}
 
-	return 0, 0, ""
+	return 0, nil, ""
 }
 
-func (v *ValidationCore) checkCellTowers(ctx context.Context, cells []model.CellTower) (float32, float32, string) {
+func (v *ValidationCore) checkCellTowers(ctx context.Context, cells []model.CellTower) (float32, []sourceFix, string) {
 	var maxConf float32 = 0
-	var avgAccuracy float32 = 0
+	var fixes []sourceFix
 
 	for _, c := range cells {
-		cached, err := v.cache.GetCell(ctx, c.CellID, c.LAC)
-		if err != nil || cached == nil {
+		cached, err := v.cache.GetCell(ctx, c.Key())
+		// Mobile and possibly relocated sources never vouch for a position
+		if err != nil || cached == nil || cached.Mobility == model.SourceMobilityMobile || cached.Quarantined {
 			continue
 		}
 
@@ -241,23 +603,25 @@ func (v *ValidationCore) checkCellTowers(ctx context.Context, cells []model.Cell
 		if conf > maxConf {
 			maxConf = conf
 		}
-		avgAccuracy += 500 // cell tower approximate accuracy in meters
+		rng := cellRange(&v.cfg.Positioning, c, cached.CoverageRadius)
+		fixes = append(fixes, newSourceFix(model.PointTypeCell, cached.Latitude, cached.Longitude, rng, cached.Confidence))
 	}
 
 	if maxConf > 0 {
-		avgAccuracy /= float32(len(cells))
-		return maxConf, avgAccuracy, "Cell tower triangulation matched"
+		return maxConf, fixes, "Cell tower triangulation matched"
 	}
 
-	return 0, 0, ""
+	return 0, nil, ""
 }
 
-func (v *ValidationCore) checkBluetooth(ctx context.Context, bt []model.BluetoothDev) (float32, float32, string) {
+func (v *ValidationCore) checkBluetooth(ctx context.Context, bt []model.BluetoothDev) (float32, []sourceFix, string) {
 	var maxConf float32 = 0
+	var fixes []sourceFix
 
 	for _, b := range bt {
 		cached, err := v.cache.GetBT(ctx, b.MAC)
-		if err != nil || cached == nil {
+		// Mobile and possibly relocated sources never vouch for a position
+		if err != nil || cached == nil || cached.Mobility == model.SourceMobilityMobile || cached.Quarantined {
 			continue
 		}
 
@@ -265,13 +629,15 @@ func (v *ValidationCore) checkBluetooth(ctx context.Context, bt []model.Bluetoot
 		if conf > maxConf {
 			maxConf = conf
 		}
+		rng := bluetoothRange(&v.cfg.Positioning, b, cached.CoverageRadius)
+		fixes = append(fixes, newSourceFix(model.PointTypeBT, cached.Latitude, cached.Longitude, rng, cached.Confidence))
 	}
 
 	if maxConf > 0 {
-		return maxConf, 2.0, "Bluetooth triangulation matched"
+		return maxConf, fixes, "Bluetooth triangulation matched"
 	}
 
-	return 0, 0, ""
+	return 0, nil, ""
 }
 
 // ============================================
@@ -279,10 +645,10 @@ func (v *ValidationCore) checkBluetooth(ctx context.Context, bt []model.Bluetoot
 // ============================================
 
 func (v *ValidationCore) determineResult(confidence float32) model.ValidationResult {
-	if confidence >= v.cfg.ConfidenceThresholds.High {
+	if float64(confidence) >= v.cfg.ConfidenceThresholds.High {
 		return model.ValidationResultValid
 	}
-	if confidence <= v.cfg.ConfidenceThresholds.Low {
+	if float64(confidence) <= v.cfg.ConfidenceThresholds.Low {
 		return model.ValidationResultInvalid
 	}
 	return model.ValidationResultUncertain
@@ -293,14 +659,59 @@ func (v *ValidationCore) determineResult(confidence float32) model.ValidationRes
 // ============================================
 
 func (v *ValidationCore) UpdateDevicePosition(ctx context.Context, req *model.CoordinateRequest) error {
+	// Store the track in server time, like Validate checked it
+	if skew, err := v.cache.GetClockSkew(ctx, req.DeviceID); err == nil {
+		req = v.correctTimestamp(req, skew)
+	}
+
+	existing, err := v.cache.GetDevicePosition(ctx, req.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	var prev *model.KalmanState
+	if existing != nil {
+		prev = existing.Kalman
+	}
+
 	pos := &model.DevicePosition{
 		DeviceID:  req.DeviceID,
 		Latitude:  req.Latitude,
 		Longitude: req.Longitude,
+		Accuracy:  req.Accuracy,
 		Timestamp: req.Timestamp,
 		LastSeen:  time.Now(),
+		Kalman:    v.kalmanStep(prev, req).state,
+	}
+	// Never regress the latest position with a late-arriving point
+	stored, err := v.cache.SetDevicePositionIfNewer(ctx, pos)
+	if err != nil {
+		return err
 	}
-	return v.cache.SetDevicePosition(ctx, pos)
+	if !stored && existing != nil && v.isBeyondReorderWindow(existing.Timestamp, req.Timestamp) {
+		return nil
+	}
+
+	return v.cache.PushTrackPoint(ctx, req.DeviceID, &model.TrackPoint{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Accuracy:  req.Accuracy,
+		Timestamp: req.Timestamp,
+	}, v.cfg.Trajectory.WindowSize, v.cfg.Trajectory.TrackTTL)
+}
+
+// isBeyondReorderWindow reports whether a point at ts arrived too late
+// relative to the latest known point to be placed into the history.
+func (v *ValidationCore) isBeyondReorderWindow(latestTs, ts int64) bool {
+	return time.Duration(latestTs-ts)*time.Second > v.cfg.Trajectory.ReorderTolerance
+}
+
+// GetTrajectory returns the device trajectory window, newest first.
+func (v *ValidationCore) GetTrajectory(ctx context.Context, deviceID string, limit int) ([]model.TrackPoint, error) {
+	if limit <= 0 || limit > v.cfg.Trajectory.WindowSize {
+		limit = v.cfg.Trajectory.WindowSize
+	}
+	return v.cache.GetTrack(ctx, deviceID, limit)
 }
 
 // ============================================
@@ -331,10 +742,21 @@ func toRad(deg float64) float64 {
 // ============================================
 
 type ValidationError struct {
-	Code    string
+	Code    model.CheckCode
 	Message string
 }
 
 func (e *ValidationError) Error() string {
-	return e.Code + ": " + e.Message
+	return string(e.Code) + ": " + e.Message
+}
+
+func (e *ValidationError) toCheck(check model.CheckName, value, threshold float64) model.CheckResult {
+	return model.CheckResult{
+		Check:     check,
+		Code:      e.Code,
+		Passed:    false,
+		Value:     value,
+		Threshold: threshold,
+		Message:   e.Message,
+	}
 }
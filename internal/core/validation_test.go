@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"coordinate-validator/internal/cache"
+	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/model"
+)
+
+// newTestCore returns a validation core with the default config, backed by
+// an in-memory Redis.
+func newTestCore(t *testing.T) (*ValidationCore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	c, err := cache.NewRedisCache(&config.RedisConfig{Addr: mr.Addr()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	cfg := config.Load().Validation
+	return NewValidationCore(c, &cfg), mr
+}
+
+const testLat, testLon = 55.751244, 37.618423
+
+// fixAt returns a fix east/north meters from the test origin at ts.
+func fixAt(deviceID string, east, north float64, ts int64) model.CoordinateRequest {
+	lat, lon := offsetLatLon(testLat, testLon, east, north)
+	return model.CoordinateRequest{DeviceID: deviceID, Latitude: lat, Longitude: lon, Accuracy: 10, Timestamp: ts}
+}
+
+// seedTrack stores accepted fixes as the device history.
+func seedTrack(t *testing.T, v *ValidationCore, fixes ...model.CoordinateRequest) {
+	t.Helper()
+	for i := range fixes {
+		if err := v.UpdateDevicePosition(context.Background(), &fixes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestValidateSpeedPlacement(t *testing.T) {
+	v, _ := newTestCore(t)
+	profile := v.profiles.Default()
+	t0 := time.Now().Unix() - 3*3600
+
+	// 36 km/h due east, one fix a minute
+	steady := func(id string) []model.CoordinateRequest {
+		return []model.CoordinateRequest{fixAt(id, 0, 0, t0), fixAt(id, 600, 0, t0+60), fixAt(id, 1200, 0, t0+120)}
+	}
+
+	tests := []struct {
+		name      string
+		track     func(id string) []model.CoordinateRequest
+		req       func(id string) model.CoordinateRequest
+		valid     bool
+		code      model.CheckCode
+		value     float64
+		threshold float64
+	}{
+		{
+			name:  "no history",
+			track: func(string) []model.CoordinateRequest { return nil },
+			req:   func(id string) model.CoordinateRequest { return fixAt(id, 0, 0, t0) },
+			valid: true,
+		},
+		{
+			name:  "next fix on the track",
+			track: steady,
+			req:   func(id string) model.CoordinateRequest { return fixAt(id, 1800, 0, t0+180) },
+			valid: true,
+		},
+		{
+			name:  "late fix between its neighbours",
+			track: steady,
+			req:   func(id string) model.CoordinateRequest { return fixAt(id, 900, 0, t0+90) },
+			valid: true,
+		},
+		{
+			name:  "late fix too fast to the following point",
+			track: steady,
+			req:   func(id string) model.CoordinateRequest { return fixAt(id, 900, 5000, t0+90) },
+			code:  model.CheckCodeNextSpeedExceeded,
+		},
+		{
+			name: "late fix too fast from the preceding points",
+			track: func(id string) []model.CoordinateRequest {
+				return []model.CoordinateRequest{fixAt(id, 0, 0, t0), fixAt(id, 600, 0, t0+60), fixAt(id, 1200, 0, t0+3000)}
+			},
+			req:  func(id string) model.CoordinateRequest { return fixAt(id, 600, 5000, t0+70) },
+			code: model.CheckCodeSpeedExceeded,
+		},
+		{
+			name: "late fix within the reorder window",
+			track: func(id string) []model.CoordinateRequest {
+				return []model.CoordinateRequest{fixAt(id, 0, 0, t0), fixAt(id, 600, 0, t0+60), fixAt(id, 1200, 0, t0+3000)}
+			},
+			req:   func(id string) model.CoordinateRequest { return fixAt(id, 900, 0, t0+1500) },
+			valid: true,
+		},
+		{
+			name: "late fix beyond the reorder window",
+			track: func(id string) []model.CoordinateRequest {
+				return []model.CoordinateRequest{fixAt(id, 0, 0, t0), fixAt(id, 600, 0, t0+60), fixAt(id, 1200, 0, t0+7200)}
+			},
+			req:       func(id string) model.CoordinateRequest { return fixAt(id, 900, 0, t0+90) },
+			code:      model.CheckCodeTooLateToPlace,
+			value:     7110,
+			threshold: 3600,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seedTrack(t, v, tt.track(tt.name)...)
+			req := tt.req(tt.name)
+
+			got, err := v.validateSpeed(context.Background(), &req, profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.valid != tt.valid {
+				t.Fatalf("valid = %v (%s), want %v", got.valid, got.reason, tt.valid)
+			}
+			if got.code != tt.code {
+				t.Errorf("code = %q, want %q", got.code, tt.code)
+			}
+			if tt.threshold > 0 && (got.value != tt.value || got.threshold != tt.threshold) {
+				t.Errorf("value/threshold = %v/%v, want %v/%v", got.value, got.threshold, tt.value, tt.threshold)
+			}
+		})
+	}
+}
+
+func TestUpdateDevicePositionReorder(t *testing.T) {
+	v, _ := newTestCore(t)
+	ctx := context.Background()
+	t0 := time.Now().Unix() - 3*3600
+
+	tests := []struct {
+		name       string
+		req        func(id string) model.CoordinateRequest
+		latestTs   int64
+		inTrack    bool
+		trackCount int
+	}{
+		{
+			name:       "newer fix becomes the latest",
+			req:        func(id string) model.CoordinateRequest { return fixAt(id, 1800, 0, t0+7260) },
+			latestTs:   t0 + 7260,
+			inTrack:    true,
+			trackCount: 4,
+		},
+		{
+			name:       "late fix within the window is placed but isn't the latest",
+			req:        func(id string) model.CoordinateRequest { return fixAt(id, 1000, 0, t0+6600) },
+			latestTs:   t0 + 7200,
+			inTrack:    true,
+			trackCount: 4,
+		},
+		{
+			name:       "late fix beyond the window is dropped",
+			req:        func(id string) model.CoordinateRequest { return fixAt(id, 300, 0, t0+30) },
+			latestTs:   t0 + 7200,
+			trackCount: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := tt.name
+			seedTrack(t, v, fixAt(id, 0, 0, t0), fixAt(id, 600, 0, t0+60), fixAt(id, 1200, 0, t0+7200))
+			req := tt.req(id)
+			seedTrack(t, v, req)
+
+			pos, err := v.cache.GetDevicePosition(ctx, id)
+			if err != nil || pos == nil {
+				t.Fatalf("position = %v, err = %v", pos, err)
+			}
+			if pos.Timestamp != tt.latestTs {
+				t.Errorf("latest timestamp = %d, want %d", pos.Timestamp, tt.latestTs)
+			}
+
+			track, err := v.cache.GetTrack(ctx, id, v.cfg.Trajectory.WindowSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(track) != tt.trackCount {
+				t.Errorf("track has %d points, want %d", len(track), tt.trackCount)
+			}
+			inTrack := false
+			for i, p := range track {
+				if p.Timestamp == req.Timestamp {
+					inTrack = true
+				}
+				if i > 0 && p.Timestamp > track[i-1].Timestamp {
+					t.Errorf("track isn't newest first: %d after %d", p.Timestamp, track[i-1].Timestamp)
+				}
+			}
+			if inTrack != tt.inTrack {
+				t.Errorf("fix in track = %v, want %v", inTrack, tt.inTrack)
+			}
+		})
+	}
+}
+
+func TestIsBeyondReorderWindow(t *testing.T) {
+	v := &ValidationCore{cfg: &config.ValidationConfig{
+		Trajectory: config.TrajectoryConfig{ReorderTolerance: time.Hour},
+	}}
+
+	tests := []struct {
+		name     string
+		latestTs int64
+		ts       int64
+		want     bool
+	}{
+		{"newer point", 1000, 2000, false},
+		{"same time", 1000, 1000, false},
+		{"just within", 4600, 1000, false},
+		{"just beyond", 4601, 1000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := v.isBeyondReorderWindow(tt.latestTs, tt.ts); got != tt.want {
+				t.Errorf("isBeyondReorderWindow(%d, %d) = %v, want %v", tt.latestTs, tt.ts, got, tt.want)
+			}
+		})
+	}
+}
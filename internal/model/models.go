@@ -1,6 +1,11 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // ============================================
 // Coordinate Validation
@@ -15,13 +20,143 @@ type CoordinateRequest struct {
 	Wifi        []WifiAP       `json:"wifi,omitempty"`
 	Bluetooth   []BluetoothDev `json:"bluetooth,omitempty"`
 	CellTowers  []CellTower    `json:"cell_towers,omitempty"`
+	GNSS        *GNSSInfo      `json:"gnss,omitempty"`
+}
+
+// GNSSInfo is the receiver metadata reported with a fix. Nil pointers and
+// zero values mean the tracker didn't report the field.
+type GNSSInfo struct {
+	Altitude   *float64     `json:"altitude,omitempty"` // meters
+	SpeedKmH   *float64     `json:"speed_kmh,omitempty"`
+	Course     *float64     `json:"course,omitempty"` // degrees
+	Satellites int32        `json:"satellites,omitempty"`
+	HDOP       float32      `json:"hdop,omitempty"`
+	PDOP       float32      `json:"pdop,omitempty"`
+	FixType    FixType      `json:"fix_type,omitempty"`
+	Provider   GNSSProvider `json:"provider,omitempty"`
 }
 
+type FixType string
+
+const (
+	FixTypeUnknown FixType = ""
+	FixTypeNone    FixType = "NO_FIX"
+	FixType2D      FixType = "FIX_2D"
+	FixType3D      FixType = "FIX_3D"
+	FixTypeDGPS    FixType = "DGPS"
+	FixTypeRTK     FixType = "RTK"
+)
+
+type GNSSProvider string
+
+const (
+	GNSSProviderUnknown GNSSProvider = ""
+	GNSSProviderGNSS    GNSSProvider = "GNSS"
+	GNSSProviderNetwork GNSSProvider = "NETWORK"
+	GNSSProviderFused   GNSSProvider = "FUSED"
+	GNSSProviderMock    GNSSProvider = "MOCK"
+)
+
 type CoordinateResponse struct {
 	Result             ValidationResult `json:"result"`
 	Confidence         float32          `json:"confidence"`
 	EstimatedAccuracy  float32          `json:"estimated_accuracy"`
 	Reason             string           `json:"reason"`
+	Estimate           *PositionEstimate `json:"estimate,omitempty"`
+	CorrectedLatitude  float64          `json:"corrected_latitude"`
+	CorrectedLongitude float64          `json:"corrected_longitude"`
+	AnomalyScore       float32          `json:"anomaly_score"`
+	SpoofingScore      float32          `json:"spoofing_score"`
+	Checks             []CheckResult    `json:"checks,omitempty"`
+}
+
+// CheckResult is the verdict of one validation check.
+type CheckResult struct {
+	Check        CheckName `json:"check"`
+	Code         CheckCode `json:"code"`
+	Passed       bool      `json:"passed"`
+	Value        float64   `json:"value"`
+	Threshold    float64   `json:"threshold"`
+	Contribution float32   `json:"contribution"` // change in confidence caused by the check
+	Message      string    `json:"message,omitempty"`
+	Subject      string    `json:"subject,omitempty"` // zone, corridor or other reference data the check refers to
+}
+
+type CheckName string
+
+const (
+	CheckSanity          CheckName = "SANITY"
+	CheckTime            CheckName = "TIME"
+	CheckClock           CheckName = "CLOCK"
+	CheckSpeed           CheckName = "SPEED"
+	CheckAcceleration    CheckName = "ACCELERATION"
+	CheckHeading         CheckName = "HEADING"
+	CheckWifi            CheckName = "WIFI"
+	CheckCell            CheckName = "CELL"
+	CheckBLE             CheckName = "BLE"
+	CheckSourceDistance  CheckName = "SOURCE_DISTANCE"
+	CheckTrack           CheckName = "TRACK"
+	CheckGeofence        CheckName = "GEOFENCE"
+	CheckGap             CheckName = "GAP"
+	CheckGNSSSpeed       CheckName = "GNSS_SPEED"
+	CheckGNSSCourse      CheckName = "GNSS_COURSE"
+	CheckGNSSQuality     CheckName = "GNSS_QUALITY"
+	CheckMCCCountry      CheckName = "MCC_COUNTRY"
+	CheckWifiFingerprint CheckName = "WIFI_FINGERPRINT"
+	CheckSpoofing        CheckName = "SPOOFING"
+)
+
+type CheckCode string
+
+const (
+	CheckCodeOK                    CheckCode = "OK"
+	CheckCodeInvalidNumber         CheckCode = "INVALID_NUMBER"
+	CheckCodeLatitudeOutOfRange    CheckCode = "LATITUDE_OUT_OF_RANGE"
+	CheckCodeLongitudeOutOfRange   CheckCode = "LONGITUDE_OUT_OF_RANGE"
+	CheckCodeNullIsland            CheckCode = "NULL_ISLAND"
+	CheckCodeInvalidAccuracy       CheckCode = "INVALID_ACCURACY"
+	CheckCodePrecisionArtifact     CheckCode = "PRECISION_ARTIFACT"
+	CheckCodeFutureTimestamp       CheckCode = "FUTURE_TIMESTAMP"
+	CheckCodeTimestampTooOld       CheckCode = "TIMESTAMP_TOO_OLD"
+	CheckCodeClockDrift            CheckCode = "CLOCK_DRIFT"
+	CheckCodeClockOffsetTooLarge   CheckCode = "CLOCK_OFFSET_TOO_LARGE"
+	CheckCodeSpeedExceeded         CheckCode = "SPEED_EXCEEDED"
+	CheckCodeNextSpeedExceeded     CheckCode = "SPEED_TO_NEXT_EXCEEDED"
+	CheckCodeTooLateToPlace        CheckCode = "TOO_LATE_TO_PLACE"
+	CheckCodeAccelerationExceeded  CheckCode = "ACCELERATION_EXCEEDED"
+	CheckCodeHeadingExceeded       CheckCode = "HEADING_CHANGE_EXCEEDED"
+	CheckCodeSourceMatched         CheckCode = "SOURCE_MATCHED"
+	CheckCodeSourceUnknown         CheckCode = "SOURCE_UNKNOWN"
+	CheckCodeOutsideSourceEstimate CheckCode = "OUTSIDE_SOURCE_ESTIMATE"
+	CheckCodeTrackDeviation        CheckCode = "TRACK_DEVIATION"
+	CheckCodeForbiddenZone         CheckCode = "INSIDE_FORBIDDEN_ZONE"
+	CheckCodeOutsideExpectedZone   CheckCode = "OUTSIDE_EXPECTED_ZONE"
+	CheckCodeCorridorExempt        CheckCode = "CORRIDOR_EXEMPT"
+	CheckCodeOutsidePredicted      CheckCode = "REAPPEARED_OUTSIDE_PREDICTED_REGION"
+	CheckCodeSpeedMismatch         CheckCode = "REPORTED_SPEED_MISMATCH"
+	CheckCodeCourseMismatch        CheckCode = "REPORTED_COURSE_MISMATCH"
+	CheckCodeImplausibleAccuracy   CheckCode = "IMPLAUSIBLE_ACCURACY"
+	CheckCodeMockProvider          CheckCode = "MOCK_PROVIDER"
+	CheckCodeNoFix                 CheckCode = "NO_FIX"
+	CheckCodeCountryMismatch       CheckCode = "MCC_COUNTRY_MISMATCH"
+	CheckCodeNearBorder            CheckCode = "NEAR_BORDER"
+	CheckCodeFingerprintMatched    CheckCode = "FINGERPRINT_MATCHED"
+	CheckCodeNeverCoVisible        CheckCode = "NEVER_CO_VISIBLE"
+	CheckCodeFingerprintMismatch   CheckCode = "FINGERPRINT_LOCATION_MISMATCH"
+	CheckCodeConstantMotion        CheckCode = "CONSTANT_MOTION"
+	CheckCodeFrozenCoordinates     CheckCode = "FROZEN_COORDINATES"
+	CheckCodeConstantAccuracy      CheckCode = "CONSTANT_ACCURACY"
+	CheckCodeGridSnapping          CheckCode = "GRID_SNAPPING"
+	CheckCodeInterleavedPositions  CheckCode = "INTERLEAVED_POSITIONS"
+	CheckCodeSharedScans           CheckCode = "SHARED_SCANS"
+)
+
+// PositionEstimate is a position computed from cached WiFi/Cell/BLE sources.
+type PositionEstimate struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Radius    float64 `json:"radius"` // meters
+	Sources   int     `json:"sources"`
 }
 
 type ValidationResult string
@@ -36,23 +171,94 @@ const (
 // WiFi / Bluetooth / Cell Models
 // ============================================
 
+// EID fields carry the EGTS signal level of sources reported without RSSI.
+
 type WifiAP struct {
-	SSID  string `json:"ssid"`
-	BSSID string `json:"bssid"`
-	RSSI  int32  `json:"rssi"`
+	SSID      string `json:"ssid"`
+	BSSID     string `json:"bssid"`
+	RSSI      int32  `json:"rssi"`
+	EID       int32  `json:"eid,omitempty"`
+	Frequency int32  `json:"frequency,omitempty"` // MHz; 0 if unknown
 }
 
 type BluetoothDev struct {
 	MAC  string `json:"mac"`
 	RSSI int32  `json:"rssi"`
+	EID  int32  `json:"eid,omitempty"`
 }
 
+// CellTower is a serving or neighbour cell as reported by the device. The
+// global identity is radio + MCC + MNC + LAC/TAC + cell ID; the remaining
+// fields are optional radio measurements.
 type CellTower struct {
-	CellID uint32 `json:"cell_id"`
-	LAC    uint32 `json:"lac"`
-	MCC    uint32 `json:"mcc"`
-	MNC    uint32 `json:"mnc"`
-	RSSI   int32  `json:"rssi"`
+	Radio         RadioType `json:"radio,omitempty"`
+	MCC           uint32    `json:"mcc"`
+	MNC           uint32    `json:"mnc"`
+	LAC           uint32    `json:"lac"`                      // LAC (GSM/UMTS/CDMA) or TAC (LTE/NR)
+	CellID        uint64    `json:"cell_id"`                  // CID, ECI (28 bit) or NCI (36 bit)
+	PCI           *uint32   `json:"pci,omitempty"`            // physical cell ID / PSC
+	EARFCN        *uint32   `json:"earfcn,omitempty"`         // (E/NR-)ARFCN
+	TimingAdvance *int32    `json:"timing_advance,omitempty"`
+	RSSI          int32     `json:"rssi"`
+	EID           int32     `json:"eid,omitempty"`
+}
+
+// Key returns the global identity of the cell.
+func (c CellTower) Key() CellKey {
+	return CellKey{Radio: c.Radio, MCC: c.MCC, MNC: c.MNC, LAC: c.LAC, CellID: c.CellID}
+}
+
+type RadioType string
+
+const (
+	RadioUnknown RadioType = ""
+	RadioGSM     RadioType = "GSM"
+	RadioUMTS    RadioType = "UMTS"
+	RadioCDMA    RadioType = "CDMA"
+	RadioLTE     RadioType = "LTE"
+	RadioNR      RadioType = "NR"
+)
+
+// CellKey is the global identity of a cell. Cell IDs are only unique within
+// an operator's area, and CID/ECI/NCI numbering differs per radio.
+type CellKey struct {
+	Radio  RadioType
+	MCC    uint32
+	MNC    uint32
+	LAC    uint32
+	CellID uint64
+}
+
+// String formats the key as radio:mcc:mnc:lac:cell_id, e.g.
+// "LTE:250:99:7701:123456789". An unknown radio is written as "UNKNOWN".
+func (k CellKey) String() string {
+	radio := k.Radio
+	if radio == RadioUnknown {
+		radio = "UNKNOWN"
+	}
+	return fmt.Sprintf("%s:%d:%d:%d:%d", radio, k.MCC, k.MNC, k.LAC, k.CellID)
+}
+
+// ParseCellKey parses a key produced by CellKey.String.
+func ParseCellKey(s string) (CellKey, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 5 {
+		return CellKey{}, fmt.Errorf("invalid cell key %q", s)
+	}
+	var k CellKey
+	if parts[0] != "UNKNOWN" {
+		k.Radio = RadioType(parts[0])
+	}
+	nums := make([]uint64, 4)
+	for i, p := range parts[1:] {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return CellKey{}, fmt.Errorf("invalid cell key %q: %w", s, err)
+		}
+		nums[i] = n
+	}
+	k.MCC, k.MNC, k.LAC, k.CellID = uint32(nums[0]), uint32(nums[1]), uint32(nums[2]), nums[3]
+	return k, nil
 }
 
 // ============================================
@@ -97,16 +303,33 @@ type CachedWifi struct {
 	Version   int64     `json:"version"`
 	ObsCount  int64     `json:"obs_count"`
 	Confidence float64  `json:"confidence"`
+	SourceStats
+	PositionSummary
+	Relocation  *RelocationCandidate `json:"relocation,omitempty"`
+	Quarantined bool                 `json:"quarantined,omitempty"`
 }
 
 type CachedCell struct {
-	CellID    uint32  `json:"cell_id"`
-	LAC       uint32  `json:"lac"`
-	Latitude  float64 `json:"lat"`
-	Longitude float64 `json:"lon"`
-	Version   int64   `json:"version"`
-	ObsCount  int64   `json:"obs_count"`
-	Confidence float64 `json:"confidence"`
+	Radio     RadioType `json:"radio,omitempty"`
+	MCC       uint32    `json:"mcc"`
+	MNC       uint32    `json:"mnc"`
+	LAC       uint32    `json:"lac"`
+	CellID    uint64    `json:"cell_id"`
+	PCI       *uint32   `json:"pci,omitempty"`
+	EARFCN    *uint32   `json:"earfcn,omitempty"`
+	Latitude  float64   `json:"lat"`
+	Longitude float64   `json:"lon"`
+	Version   int64     `json:"version"`
+	ObsCount  int64     `json:"obs_count"`
+	Confidence float64  `json:"confidence"`
+	SourceStats
+	PositionSummary
+	Relocation  *RelocationCandidate `json:"relocation,omitempty"`
+	Quarantined bool                 `json:"quarantined,omitempty"`
+}
+
+func (c *CachedCell) Key() CellKey {
+	return CellKey{Radio: c.Radio, MCC: c.MCC, MNC: c.MNC, LAC: c.LAC, CellID: c.CellID}
 }
 
 type CachedBT struct {
@@ -117,14 +340,230 @@ type CachedBT struct {
 	Version   int64   `json:"version"`
 	ObsCount  int64   `json:"obs_count"`
 	Confidence float64 `json:"confidence"`
+	SourceStats
+	PositionSummary
+	Relocation  *RelocationCandidate `json:"relocation,omitempty"`
+	Quarantined bool                 `json:"quarantined,omitempty"`
+}
+
+// SourceStats is the Welford running mean and variance of the positions a
+// source was learned at, and the mobility class derived from them.
+type SourceStats struct {
+	Samples  int64          `json:"samples"`
+	MeanLat  float64        `json:"mean_lat"`
+	MeanLon  float64        `json:"mean_lon"`
+	M2Lat    float64        `json:"m2_lat"`
+	M2Lon    float64        `json:"m2_lon"`
+	Mobility SourceMobility `json:"mobility,omitempty"`
+}
+
+// Add folds one learned position into the running statistics.
+func (s *SourceStats) Add(lat, lon float64) {
+	s.Samples++
+	n := float64(s.Samples)
+	dLat := lat - s.MeanLat
+	s.MeanLat += dLat / n
+	s.M2Lat += dLat * (lat - s.MeanLat)
+	dLon := lon - s.MeanLon
+	s.MeanLon += dLon / n
+	s.M2Lon += dLon * (lon - s.MeanLon)
+}
+
+// Variance returns the sample variance of latitude and longitude in
+// degrees², zero below two samples.
+func (s *SourceStats) Variance() (lat, lon float64) {
+	if s.Samples < 2 {
+		return 0, 0
+	}
+	n := float64(s.Samples - 1)
+	return s.M2Lat / n, s.M2Lon / n
+}
+
+// PositionSummary is a bounded uniform sample (reservoir) of the positions
+// a source was learned at, and how far they scatter around its position.
+type PositionSummary struct {
+	Reservoir      []PositionSample `json:"reservoir,omitempty"`
+	Offered        int64            `json:"offered,omitempty"`         // positions offered to the reservoir
+	Spread         float64          `json:"spread,omitempty"`          // weighted median distance, meters
+	CoverageRadius float64          `json:"coverage_radius,omitempty"` // meters; 0 until classified
+}
+
+// PositionSample is a learned position weighted by the fix accuracy and
+// the source signal.
+type PositionSample struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Weight    float64 `json:"w"`
+}
+
+// ExcludedSource is a source learning refuses to position.
+type ExcludedSource struct {
+	PointID    string          `json:"point_id"`
+	PointType  PointType       `json:"point_type"`
+	Reason     ExclusionReason `json:"reason"`
+	SSID       string          `json:"ssid,omitempty"`
+	DetectedAt int64           `json:"detected_at"` // first exclusion
+}
+
+type ExclusionReason string
+
+const (
+	ExclusionRandomizedMAC ExclusionReason = "RANDOMIZED_MAC"
+	ExclusionHotspotSSID   ExclusionReason = "HOTSPOT_SSID"
+	ExclusionMobile        ExclusionReason = "MOBILE"
+)
+
+// RelocationCandidate collects consecutive learned positions that are far
+// from a source's cached position and agree with each other.
+type RelocationCandidate struct {
+	Latitude  float64 `json:"lat"` // mean of the far positions
+	Longitude float64 `json:"lon"`
+	Count     int64   `json:"count"`
+	Since     int64   `json:"since"` // timestamp of the first far position
+}
+
+// RelocationEvent records a confirmed move of a source.
+type RelocationEvent struct {
+	PointID        string    `json:"point_id"`
+	PointType      PointType `json:"point_type"`
+	FromLatitude   float64   `json:"from_lat"`
+	FromLongitude  float64   `json:"from_lon"`
+	ToLatitude     float64   `json:"to_lat"`
+	ToLongitude    float64   `json:"to_lon"`
+	DistanceMeters float64   `json:"distance_meters"`
+	Observations   int64     `json:"observations"`
+	FirstSeen      int64     `json:"first_seen"` // first far position
+	DetectedAt     int64     `json:"detected_at"`
+}
+
+type SourceMobility string
+
+const (
+	SourceMobilityUndetermined SourceMobility = "UNDETERMINED"
+	SourceMobilityStationary   SourceMobility = "STATIONARY"
+	SourceMobilityMobile       SourceMobility = "MOBILE"
+)
+
+// WifiFingerprint is the set of access points seen together around a
+// location, learned from scans within one grid cell.
+type WifiFingerprint struct {
+	ID        string                   `json:"id"`
+	Latitude  float64                  `json:"lat"` // mean scan position
+	Longitude float64                  `json:"lon"`
+	Scans     int64                    `json:"scans"`
+	APs       map[string]FingerprintAP `json:"aps"` // by BSSID
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+type FingerprintAP struct {
+	Seen        int64   `json:"seen"` // scans the AP appeared in
+	RSSI        float64 `json:"rssi"` // mean RSSI over RSSISamples
+	RSSISamples int64   `json:"rssi_samples"`
 }
 
 type DevicePosition struct {
 	DeviceID  string    `json:"device_id"`
 	Latitude  float64   `json:"lat"`
 	Longitude float64   `json:"lon"`
+	Accuracy  float32   `json:"accuracy"`
 	Timestamp int64     `json:"timestamp"`
 	LastSeen  time.Time `json:"last_seen"`
+	Kalman    *KalmanState `json:"kalman,omitempty"`
+}
+
+// KalmanState is the per-device constant-velocity track filter state.
+// Covariances are per axis as [p00, p01, p11] in meters and m/s.
+type KalmanState struct {
+	Latitude  float64    `json:"lat"`
+	Longitude float64    `json:"lon"`
+	VelEast   float64    `json:"vel_east"`
+	VelNorth  float64    `json:"vel_north"`
+	CovEast   [3]float64 `json:"cov_east"`
+	CovNorth  [3]float64 `json:"cov_north"`
+	Timestamp int64      `json:"timestamp"`
+}
+
+// TrackPoint is one accepted fix in a device's trajectory window.
+type TrackPoint struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Accuracy  float32 `json:"accuracy"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// ClockSkew is the learned offset of a device clock relative to server time.
+// Positive offsets mean the device clock runs ahead.
+type ClockSkew struct {
+	OffsetSeconds    float64 `json:"offset"`
+	DeviationSeconds float64 `json:"deviation"`
+	Samples          int64   `json:"samples"`
+	Drifting         bool    `json:"drifting"`
+	UpdatedAt        int64   `json:"updated_at"`
+	// Observations collected until the estimate warms up
+	WarmUp []float64 `json:"warm_up,omitempty"`
+}
+
+// ============================================
+// Device Profiles
+// ============================================
+
+// DeviceProfile holds per device-class validation limits. Zero fields fall
+// back to the global validation config.
+type DeviceProfile struct {
+	Name               string        `json:"name"`
+	Description        string        `json:"description,omitempty"`
+	MaxSpeedKmH        float64       `json:"max_speed_kmh"`
+	MaxAccelerationMS2 float64       `json:"max_acceleration_ms2"`
+	MaxTimeDiff        time.Duration `json:"max_time_diff"`
+}
+
+const DefaultProfileName = "default"
+
+// ============================================
+// Geofences
+// ============================================
+
+type GeofenceKind string
+
+const (
+	GeofenceForbidden GeofenceKind = "FORBIDDEN" // device can never be inside
+	GeofenceExpected  GeofenceKind = "EXPECTED"  // device must stay inside
+)
+
+// Polygon is a list of rings of [lon, lat] pairs as in GeoJSON; the first
+// ring is the outer boundary, the rest are holes.
+type Polygon [][][2]float64
+
+// Geofence is a polygon zone with its verdict impact. Penalty is the
+// fraction of confidence removed on violation; 1 always yields INVALID.
+type Geofence struct {
+	ID             string       `json:"id"`
+	Name           string       `json:"name,omitempty"`
+	Kind           GeofenceKind `json:"kind"`
+	Penalty        float64      `json:"penalty"`
+	DevicePrefixes []string     `json:"device_prefixes,omitempty"` // empty applies to every device
+	Polygons       []Polygon    `json:"polygons"`
+}
+
+// ============================================
+// Transit Corridors
+// ============================================
+
+type GeoPoint struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+// Corridor is a ferry, train or flight route. Jumps between two fixes that
+// stay within BufferMeters of the path are checked against MaxSpeedKmH
+// instead of the device limit.
+type Corridor struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name,omitempty"`
+	Mode         string     `json:"mode,omitempty"` // ferry, train, flight
+	Path         []GeoPoint `json:"path"`
+	BufferMeters float64    `json:"buffer_meters"`
+	MaxSpeedKmH  float64    `json:"max_speed_kmh"`
 }
 
 // ============================================
@@ -157,18 +596,87 @@ type LearningEvent struct {
 	EventTime   time.Time      `json:"event_time"`
 }
 
+// AlertEvent reports a device whose data looks manipulated.
+type AlertEvent struct {
+	Type           AlertType   `json:"type"`
+	DeviceID       string      `json:"device_id"`
+	RelatedDevices []string    `json:"related_devices,omitempty"`
+	Score          float32     `json:"score"`
+	Reasons        []CheckCode `json:"reasons"`
+	Latitude       float64     `json:"latitude"`
+	Longitude      float64     `json:"longitude"`
+	Timestamp      int64       `json:"timestamp"`
+	EventTime      time.Time   `json:"event_time"`
+}
+
+type AlertType string
+
+const (
+	AlertTypeSpoofing     AlertType = "SPOOFING"
+	AlertTypeClonedDevice AlertType = "CLONED_DEVICE"
+)
+
+// ============================================
+// Cloned Trackers
+// ============================================
+
+// Sighting is one raw fix of a device, kept whatever its verdict.
+type Sighting struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// CloneSuspect is a device reporting from places too far apart in turn
+// (INTERLEAVED_POSITIONS), or a pair of devices reporting identical WiFi
+// scans (SHARED_SCANS).
+type CloneSuspect struct {
+	Reason    CheckCode `json:"reason"`
+	DeviceIDs []string  `json:"device_ids"` // sorted
+	Count     int       `json:"count"`      // impossible returns or shared scans
+	Distance  float64   `json:"distance"`   // meters between the places, interleaved only
+	Latitude  float64   `json:"lat"`        // latest evidence
+	Longitude float64   `json:"lon"`
+	FirstSeen int64     `json:"first_seen"`
+	LastSeen  int64     `json:"last_seen"`
+}
+
+// ID identifies the suspect by reason and devices.
+func (s *CloneSuspect) ID() string {
+	return string(s.Reason) + ":" + strings.Join(s.DeviceIDs, "|")
+}
+
 // ============================================
 // Companion Detection
 // ============================================
 
+// CompanionSource is what co-occurrence analysis knows about a source seen
+// by an object. A companion travels with the object (in-vehicle BLE, a
+// hotspot on board); a stationary source stays in one place.
 type CompanionSource struct {
 	PointID       string    `json:"point_id"`
 	PointType     PointType `json:"point_type"`
-	Observations  int32     `json:"observations"`
-	Stability     float32   `json:"stability"`
+	Observations  int32     `json:"observations"` // time windows it was seen in
+	Stability     float32   `json:"stability"`    // share of the object's windows since first seen
 	IsStationary bool      `json:"is_stationary"`
+	IsCompanion   bool      `json:"is_companion"`
+	Places        int32     `json:"places"`       // places it was seen in, counted on each change
 	FirstSeen     int64     `json:"first_seen"`
 	LastSeen      int64     `json:"last_seen"`
+	BaseWindows   int32     `json:"base_windows"` // object windows before it was first seen
+	LastWindow    int64     `json:"last_window"`
+	LastPlace     string    `json:"last_place"`
+}
+
+// CompanionKey identifies a source among an object's companions.
+func CompanionKey(pointType PointType, pointID string) string {
+	return string(pointType) + ":" + pointID
+}
+
+// CompanionTrack counts the time windows an object reported sources in.
+type CompanionTrack struct {
+	Windows    int32 `json:"windows"`
+	LastWindow int64 `json:"last_window"`
 }
 
 type PointType string
@@ -192,6 +700,7 @@ type ValidationRecord struct {
 	HasWifi      bool            `json:"has_wifi"`
 	HasBT        bool            `json:"has_bt"`
 	HasCell      bool            `json:"has_cell"`
+	CellIDs      []string        `json:"cell_ids,omitempty"` // CellKey strings
 	Result       ValidationResult `json:"result"`
 	Confidence   float32         `json:"confidence"`
 	FlowType     string          `json:"flow_type"` // "refinement" or "learning"
@@ -15,6 +15,7 @@ import (
 type KafkaProducer struct {
 	refinementWriter *kafka.Writer
 	learningWriter   *kafka.Writer
+	alertWriter      *kafka.Writer
 	cfg              *config.KafkaConfig
 }
 
@@ -33,9 +34,17 @@ func NewKafkaProducer(cfg *config.KafkaConfig) *KafkaProducer {
 		Async:    true,
 	}
 
+	alertWriter := &kafka.Writer{
+		Addr:     kafka.TCP(cfg.Brokers...),
+		Topic:    cfg.AlertTopic,
+		Balancer: &kafka.Hash{},
+		Async:    true,
+	}
+
 	return &KafkaProducer{
 		refinementWriter: refinementWriter,
 		learningWriter:   learningWriter,
+		alertWriter:      alertWriter,
 		cfg:              cfg,
 	}
 }
@@ -44,7 +53,10 @@ func (p *KafkaProducer) Close() error {
 	if err := p.refinementWriter.Close(); err != nil {
 		return err
 	}
-	return p.learningWriter.Close()
+	if err := p.learningWriter.Close(); err != nil {
+		return err
+	}
+	return p.alertWriter.Close()
 }
 
 // ============================================
@@ -91,6 +103,30 @@ func (p *KafkaProducer) SendLearningEvent(ctx context.Context, event *model.Lear
 	return p.learningWriter.WriteMessages(ctx, msg)
 }
 
+// ============================================
+// Alert Events
+// ============================================
+
+// SendAlert publishes an alert keyed by device, so a device's alerts stay
+// ordered.
+func (p *KafkaProducer) SendAlert(ctx context.Context, event *model.AlertEvent) error {
+	event.EventTime = time.Now()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(event.DeviceID),
+		Value: data,
+	}
+
+	log.Printf("[Kafka] Sending %s alert: device=%s, score=%.2f", event.Type, event.DeviceID, event.Score)
+
+	return p.alertWriter.WriteMessages(ctx, msg)
+}
+
 // ============================================
 // Batch Operations
 // ============================================
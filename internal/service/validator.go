@@ -3,13 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
-	"math"
-	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"coordinate-validator/internal/cache"
 	"coordinate-validator/internal/config"
+	"coordinate-validator/internal/core"
+	"coordinate-validator/internal/model"
 	"coordinate-validator/internal/storage"
 	pb "coordinate-validator/pkg/pb"
 )
@@ -18,6 +19,11 @@ type ValidatorService struct {
 	cache   *cache.RedisCache
 	storage  *storage.ClickHouseStorage
 	cfg      config.ValidationConfig
+	profiles *core.ProfileRegistry
+	geofences *core.GeofenceRegistry
+	corridors *core.CorridorRegistry
+	validator *core.ValidationCore
+	learning  *core.LearningCore
 	wg       sync.WaitGroup
 	cacheMu  sync.Mutex
 	pb.UnimplementedCoordinateValidatorServer
@@ -28,47 +34,81 @@ func NewValidatorService(
 	storage *storage.ClickHouseStorage,
 	cfg config.ValidationConfig,
 ) *ValidatorService {
-	return &ValidatorService{
+	s := &ValidatorService{
 		cache:  cache,
 		storage: storage,
 		cfg:     cfg,
 	}
+	s.validator = core.NewValidationCore(cache, &s.cfg)
+	// Admin changes go through the registries validation reads, so they
+	// apply without waiting for a refresh
+	s.profiles = s.validator.Profiles()
+	s.geofences = s.validator.Geofences()
+	s.corridors = s.validator.Corridors()
+	s.learning = core.NewLearningCore(cache, &s.cfg)
+	return s
 }
 
 // ============ CoordinateValidator Service ============
 
 func (s *ValidatorService) Validate(ctx context.Context, req *pb.CoordinateRequest) (*pb.CoordinateResponse, error) {
+	if sanity, err := core.CheckCoordinateSanity(&s.cfg.Sanity, req.Latitude, req.Longitude, req.Accuracy); err != nil {
+		check := newCheck(sanity.Check, sanity.Value, sanity.Threshold)
+		check.fail(sanity.Code, sanity.Message)
+		return &pb.CoordinateResponse{
+			Result: pb.ValidationResult_INVALID,
+			Reason: err.Error(),
+			Checks: []*pb.CheckResult{check.result()},
+		}, nil
+	}
+
 	result := pb.ValidationResult_VALID
 	confidence := float32(1.0)
 	reasons := []string{}
-
+	checks := []*pb.CheckResult{}
+	profile := s.profiles.Resolve(ctx, req.DeviceId)
+
+	// Check timestamps in server time once the device clock is learned
+	timestamp := s.validator.CorrectedTimestamp(ctx, &model.CoordinateRequest{
+		DeviceID:  req.DeviceId,
+		Timestamp: req.Timestamp,
+	})
 	now := time.Now()
-	reqTime := time.Unix(req.Timestamp, 0)
+	reqTime := time.Unix(timestamp, 0)
 	timeDiff := now.Sub(reqTime)
 
-	if timeDiff < 0 {
+	timeCheck := newCheck(model.CheckTime, timeDiff.Seconds(), profile.MaxTimeDiff.Seconds())
+	if timeDiff < -s.cfg.Clock.FutureTolerance {
 		result = pb.ValidationResult_INVALID
 		reasons = append(reasons, "timestamp in the future")
-	} else if timeDiff > s.cfg.MaxTimeDiff {
+		timeCheck.fail(model.CheckCodeFutureTimestamp, "timestamp in the future")
+	} else if timeDiff > profile.MaxTimeDiff {
 		result = pb.ValidationResult_INVALID
 		reasons = append(reasons, fmt.Sprintf("timestamp too old: %v", timeDiff))
+		timeCheck.fail(model.CheckCodeTimestampTooOld, fmt.Sprintf("timestamp too old: %v", timeDiff))
 	}
+	checks = append(checks, timeCheck.result())
 
-	lastLoc, err := s.cache.GetLastKnownLocation(ctx, req.DeviceId)
-	if err == nil && lastLoc != nil && result != pb.ValidationResult_INVALID {
-		speed := calculateSpeedKmH(lastLoc.Lat, lastLoc.Lon, lastLoc.Time, req.Latitude, req.Longitude, reqTime)
-		if speed > s.cfg.MaxSpeedKmH {
+	track, err := s.cache.GetTrack(ctx, req.DeviceId, s.cfg.Trajectory.WindowSize)
+	if err == nil && len(track) > 0 && result != pb.ValidationResult_INVALID {
+		speed := core.MedianSpeedKmH(track, req.Latitude, req.Longitude, req.Accuracy, timestamp)
+		speedCheck := newCheck(model.CheckSpeed, speed, profile.MaxSpeedKmH)
+		if speed > profile.MaxSpeedKmH {
 			result = pb.ValidationResult_INVALID
 			reasons = append(reasons, fmt.Sprintf("impossible speed: %.1f km/h", speed))
+			speedCheck.fail(model.CheckCodeSpeedExceeded, fmt.Sprintf("impossible speed: %.1f km/h", speed))
 		}
+		checks = append(checks, speedCheck.result())
 	}
 
 	if len(req.Wifi) > 0 && result != pb.ValidationResult_INVALID {
 		hasKnownWifi := false
+		wifiCheck := newSourceCheck(model.CheckWifi)
 		for _, wifi := range req.Wifi {
 			wifiPoint, err := s.cache.GetWifiPoint(ctx, wifi.Bssid)
 			if err == nil && wifiPoint != nil {
 				confidence += s.cfg.WifiWeight * 0.3
+				wifiCheck.match(s.cfg.WifiWeight * 0.3)
 				hasKnownWifi = true
 				reasons = append(reasons, fmt.Sprintf("known WiFi: %s", wifi.Bssid))
 			} else {
@@ -88,43 +128,51 @@ func (s *ValidatorService) Validate(ctx context.Context, req *pb.CoordinateReque
 		if hasKnownWifi {
 			reasons = append(reasons, "known WiFi access points found")
 		}
+		checks = append(checks, wifiCheck.result())
 	}
 
 	if len(req.Bluetooth) > 0 && result != pb.ValidationResult_INVALID {
+		btCheck := newSourceCheck(model.CheckBLE)
 		for _, bt := range req.Bluetooth {
 			btPoint, err := s.cache.GetBluetoothPoint(ctx, bt.Mac)
 			if err == nil && btPoint != nil {
 				confidence += s.cfg.BluetoothWeight * 0.3
+				btCheck.match(s.cfg.BluetoothWeight * 0.3)
 				reasons = append(reasons, fmt.Sprintf("known BLE: %s", bt.Mac))
 			}
 		}
+		checks = append(checks, btCheck.result())
 	}
 
 	if len(req.CellTowers) > 0 && result != pb.ValidationResult_INVALID {
 		hasKnownCell := false
+		cellCheck := newSourceCheck(model.CheckCell)
 		for _, cell := range req.CellTowers {
-			cellPoint, err := s.cache.GetCellPoint(ctx, cell.CellId, cell.Lac)
+			key := cellKeyFromPB(cell)
+			cellPoint, err := s.cache.GetCellPoint(ctx, key)
 			if err == nil && cellPoint != nil {
 				confidence += s.cfg.CellWeight * 0.3
+				cellCheck.match(s.cfg.CellWeight * 0.3)
 				hasKnownCell = true
-				reasons = append(reasons, fmt.Sprintf("known cell: CID=%d LAC=%d", cell.CellId, cell.Lac))
+				reasons = append(reasons, fmt.Sprintf("known cell: %s", key))
 			} else {
 				rssi := cell.Rssi
 				if rssi == 0 && cell.Eid != 0 {
 					rssi = cache.ConvertEIDToRSSI(cell.Eid)
 				}
 				s.wg.Add(1)
-				go func(cellID, lac, mcc, mnc uint32, lat, lon float64, rssi int32) {
+				go func(key model.CellKey, lat, lon float64, rssi int32) {
 					defer s.wg.Done()
 					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 					defer cancel()
-					s.recordCellPointFromEGTS(ctx, cellID, lac, mcc, mnc, lat, lon, rssi)
-				}(cell.CellId, cell.Lac, cell.Mcc, cell.Mnc, req.Latitude, req.Longitude, rssi)
+					s.recordCellPointFromEGTS(ctx, key, lat, lon, rssi)
+				}(key, req.Latitude, req.Longitude, rssi)
 			}
 		}
 		if hasKnownCell {
 			reasons = append(reasons, "known cell towers found")
 		}
+		checks = append(checks, cellCheck.result())
 	}
 
 	if confidence > 1.0 {
@@ -151,21 +199,23 @@ func (s *ValidatorService) Validate(ctx context.Context, req *pb.CoordinateReque
 
 	if result != pb.ValidationResult_INVALID {
 		s.wg.Add(1)
-		go func(deviceID string, lat, lon float64, t time.Time) {
+		go func(deviceID string, lat, lon float64, acc float32, t time.Time) {
 			defer s.wg.Done()
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
-			s.cache.SetLastKnownLocation(ctx, deviceID, &cache.LastKnownLocation{Lat: lat, Lon: lon, Time: t})
-		}(req.DeviceId, req.Latitude, req.Longitude, reqTime)
+			point := &model.TrackPoint{Latitude: lat, Longitude: lon, Accuracy: acc, Timestamp: t.Unix()}
+			s.cache.PushTrackPoint(ctx, deviceID, point, s.cfg.Trajectory.WindowSize, s.cfg.Trajectory.TrackTTL)
+		}(req.DeviceId, req.Latitude, req.Longitude, req.Accuracy, reqTime)
 	}
 
 	response := &pb.CoordinateResponse{
 		Result:            result,
 		Confidence:       confidence,
 		EstimatedAccuracy: req.Accuracy,
+		Checks:            checks,
 	}
 	if len(reasons) > 0 {
-		response.Reason = fmt.Sprintf("; ", reasons...)
+		response.Reason = strings.Join(reasons, "; ")
 	}
 	return response, nil
 }
@@ -192,6 +242,10 @@ func (s *ValidatorService) ValidateBatch(stream pb.CoordinateValidator_ValidateB
 // ============ LearningService ============
 
 func (s *ValidatorService) LearnFromCoordinates(ctx context.Context, req *pb.LearnRequest) (*pb.LearnResponse, error) {
+	if _, err := core.CheckCoordinateSanity(&s.cfg.Sanity, req.Latitude, req.Longitude, req.Accuracy); err != nil {
+		return nil, err
+	}
+
 	stationarySources := []string{}
 	randomSources := []string{}
 
@@ -218,7 +272,7 @@ func (s *ValidatorService) LearnFromCoordinates(ctx context.Context, req *pb.Lea
 	}
 
 	for _, cell := range req.CellTowers {
-		cellID := fmt.Sprintf("%d:%d", cell.CellId, cell.Lac)
+		cellID := cellKeyFromPB(cell).String()
 		obs, err := s.cache.AddObservation(ctx, req.ObjectId, "cell", cellID, req.Latitude, req.Longitude)
 		if err == nil && obs != nil {
 			if obs.Status == "STATIONARY" {
@@ -246,7 +300,29 @@ func (s *ValidatorService) LearnFromCoordinates(ctx context.Context, req *pb.Lea
 }
 
 func (s *ValidatorService) GetCompanionSources(ctx context.Context, req *pb.GetCompanionsRequest) (*pb.GetCompanionsResponse, error) {
-	return &pb.GetCompanionsResponse{Companions: []*pb.CompanionSource{}}, nil
+	var pointType model.PointType
+	if req.PointType != pb.PointType_POINT_TYPE_UNSPECIFIED {
+		pointType = model.PointType(req.PointType.String())
+	}
+	companions, err := s.learning.Companions(ctx, req.ObjectId, pointType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetCompanionsResponse{Companions: make([]*pb.CompanionSource, 0, len(companions))}
+	for _, c := range companions {
+		resp.Companions = append(resp.Companions, &pb.CompanionSource{
+			PointId:      c.PointID,
+			PointType:    pb.PointType(pb.PointType_value[string(c.PointType)]),
+			Observations: c.Observations,
+			Stability:    c.Stability,
+			IsStationary: c.IsStationary,
+			FirstSeen:    c.FirstSeen,
+			LastSeen:     c.LastSeen,
+			Places:       c.Places,
+		})
+	}
+	return resp, nil
 }
 
 // ============ AbsoluteCoordinates Service ============
@@ -300,11 +376,72 @@ func (s *ValidatorService) GetPointInfo(ctx context.Context, req *pb.PointReques
 	if obs != nil {
 		resp.IsStationary = obs.Status == "STATIONARY"
 	}
+	resp.Quarantined = s.isQuarantined(ctx, req.PointType, req.PointId)
+
+	moves, err := s.cache.GetRelocations(ctx, model.PointType(pointType), req.PointId)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range moves {
+		resp.Relocations = append(resp.Relocations, &pb.RelocationEvent{
+			FromLatitude:   m.FromLatitude,
+			FromLongitude:  m.FromLongitude,
+			ToLatitude:     m.ToLatitude,
+			ToLongitude:    m.ToLongitude,
+			DistanceMeters: m.DistanceMeters,
+			Observations:   m.Observations,
+			FirstSeen:      m.FirstSeen,
+			DetectedAt:     m.DetectedAt,
+		})
+	}
 	return resp, nil
 }
 
+// isQuarantined reports whether the learned source is held back from
+// validation because it seems to have moved.
+func (s *ValidatorService) isQuarantined(ctx context.Context, pointType pb.PointType, pointID string) bool {
+	switch pointType {
+	case pb.PointType_WIFI:
+		w, _ := s.cache.GetWifi(ctx, pointID)
+		return w != nil && w.Quarantined
+	case pb.PointType_BLE:
+		b, _ := s.cache.GetBT(ctx, pointID)
+		return b != nil && b.Quarantined
+	case pb.PointType_CELL:
+		key, err := model.ParseCellKey(pointID)
+		if err != nil {
+			return false
+		}
+		c, _ := s.cache.GetCell(ctx, key)
+		return c != nil && c.Quarantined
+	}
+	return false
+}
+
 func (s *ValidatorService) GetExcludedPoints(ctx context.Context, req *pb.ExcludedRequest) (*pb.ExcludedResponse, error) {
-	return &pb.ExcludedResponse{Sources: []*pb.ExcludedSource{}}, nil
+	var pointType model.PointType
+	if req.PointType != pb.PointType_POINT_TYPE_UNSPECIFIED {
+		pointType = model.PointType(req.PointType.String())
+	}
+	limit := int64(req.Limit)
+	if limit <= 0 {
+		limit = 100
+	}
+
+	excluded, err := s.cache.ListExcludedSources(ctx, pointType, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ExcludedResponse{Sources: make([]*pb.ExcludedSource, 0, len(excluded))}
+	for _, e := range excluded {
+		resp.Sources = append(resp.Sources, &pb.ExcludedSource{
+			PointId:    e.PointID,
+			PointType:  pb.PointType(pb.PointType_value[string(e.PointType)]),
+			Reason:     string(e.Reason),
+			DetectedAt: e.DetectedAt,
+		})
+	}
+	return resp, nil
 }
 
 // ============ AdminService ============
@@ -332,6 +469,132 @@ func (s *ValidatorService) GetConfigHistory(ctx context.Context, req *pb.History
 	return &pb.HistoryResponse{Changes: []*pb.ConfigChange{}}, nil
 }
 
+func (s *ValidatorService) ListDeviceProfiles(ctx context.Context, req *pb.ListDeviceProfilesRequest) (*pb.ListDeviceProfilesResponse, error) {
+	profiles, err := s.profiles.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices, prefixes, err := s.profiles.Assignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListDeviceProfilesResponse{
+		Profiles: []*pb.DeviceProfile{toPBProfile(s.profiles.Default())},
+	}
+	for i := range profiles {
+		resp.Profiles = append(resp.Profiles, toPBProfile(&profiles[i]))
+	}
+	for deviceID, name := range devices {
+		resp.Assignments = append(resp.Assignments, &pb.ProfileAssignment{DeviceId: deviceID, Profile: name})
+	}
+	for prefix, name := range prefixes {
+		resp.Assignments = append(resp.Assignments, &pb.ProfileAssignment{Prefix: prefix, Profile: name})
+	}
+	return resp, nil
+}
+
+func (s *ValidatorService) SetDeviceProfile(ctx context.Context, req *pb.SetDeviceProfileRequest) (*pb.SetDeviceProfileResponse, error) {
+	if req.Profile == nil {
+		return &pb.SetDeviceProfileResponse{Success: false}, fmt.Errorf("profile is required")
+	}
+	err := s.profiles.Set(ctx, &model.DeviceProfile{
+		Name:               req.Profile.Name,
+		Description:        req.Profile.Description,
+		MaxSpeedKmH:        req.Profile.MaxSpeedKmh,
+		MaxAccelerationMS2: req.Profile.MaxAccelerationMs2,
+		MaxTimeDiff:        time.Duration(req.Profile.MaxTimeDiffSeconds) * time.Second,
+	})
+	return &pb.SetDeviceProfileResponse{Success: err == nil}, err
+}
+
+func (s *ValidatorService) DeleteDeviceProfile(ctx context.Context, req *pb.DeleteDeviceProfileRequest) (*pb.DeleteDeviceProfileResponse, error) {
+	err := s.profiles.Delete(ctx, req.Name)
+	return &pb.DeleteDeviceProfileResponse{Success: err == nil}, err
+}
+
+func (s *ValidatorService) AssignDeviceProfile(ctx context.Context, req *pb.AssignDeviceProfileRequest) (*pb.AssignDeviceProfileResponse, error) {
+	err := s.profiles.Assign(ctx, req.DeviceId, req.Prefix, req.Profile)
+	return &pb.AssignDeviceProfileResponse{Success: err == nil}, err
+}
+
+func (s *ValidatorService) ListGeofences(ctx context.Context, req *pb.ListGeofencesRequest) (*pb.ListGeofencesResponse, error) {
+	zones, err := s.geofences.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListGeofencesResponse{}
+	for i := range zones {
+		zone, err := toPBGeofence(&zones[i])
+		if err != nil {
+			return nil, err
+		}
+		resp.Geofences = append(resp.Geofences, zone)
+	}
+	return resp, nil
+}
+
+func (s *ValidatorService) SetGeofence(ctx context.Context, req *pb.SetGeofenceRequest) (*pb.SetGeofenceResponse, error) {
+	if req.Geofence == nil {
+		return &pb.SetGeofenceResponse{Success: false}, fmt.Errorf("geofence is required")
+	}
+	polygons, err := core.ParseGeometry([]byte(req.Geofence.Geometry))
+	if err != nil {
+		return &pb.SetGeofenceResponse{Success: false}, fmt.Errorf("invalid geometry: %w", err)
+	}
+	err = s.geofences.Set(ctx, &model.Geofence{
+		ID:             req.Geofence.Id,
+		Name:           req.Geofence.Name,
+		Kind:           model.GeofenceKind(strings.ToUpper(req.Geofence.Kind)),
+		Penalty:        req.Geofence.Penalty,
+		DevicePrefixes: req.Geofence.DevicePrefixes,
+		Polygons:       polygons,
+	})
+	return &pb.SetGeofenceResponse{Success: err == nil}, err
+}
+
+func (s *ValidatorService) DeleteGeofence(ctx context.Context, req *pb.DeleteGeofenceRequest) (*pb.DeleteGeofenceResponse, error) {
+	err := s.geofences.Delete(ctx, req.Id)
+	return &pb.DeleteGeofenceResponse{Success: err == nil}, err
+}
+
+func (s *ValidatorService) ListCorridors(ctx context.Context, req *pb.ListCorridorsRequest) (*pb.ListCorridorsResponse, error) {
+	corridors, err := s.corridors.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListCorridorsResponse{}
+	for i := range corridors {
+		resp.Corridors = append(resp.Corridors, toPBCorridor(&corridors[i]))
+	}
+	return resp, nil
+}
+
+func (s *ValidatorService) SetCorridor(ctx context.Context, req *pb.SetCorridorRequest) (*pb.SetCorridorResponse, error) {
+	if req.Corridor == nil {
+		return &pb.SetCorridorResponse{Success: false}, fmt.Errorf("corridor is required")
+	}
+	corridor := &model.Corridor{
+		ID:           req.Corridor.Id,
+		Name:         req.Corridor.Name,
+		Mode:         req.Corridor.Mode,
+		BufferMeters: req.Corridor.BufferMeters,
+		MaxSpeedKmH:  req.Corridor.MaxSpeedKmh,
+	}
+	for _, p := range req.Corridor.Path {
+		corridor.Path = append(corridor.Path, model.GeoPoint{Latitude: p.Latitude, Longitude: p.Longitude})
+	}
+	err := s.corridors.Set(ctx, corridor)
+	return &pb.SetCorridorResponse{Success: err == nil}, err
+}
+
+func (s *ValidatorService) DeleteCorridor(ctx context.Context, req *pb.DeleteCorridorRequest) (*pb.DeleteCorridorResponse, error) {
+	err := s.corridors.Delete(ctx, req.Id)
+	return &pb.DeleteCorridorResponse{Success: err == nil}, err
+}
+
 // ============ MetricsService ============
 
 func (s *ValidatorService) GetOverview(ctx context.Context, req *pb.OverviewRequest) (*pb.OverviewResponse, error) {
@@ -382,22 +645,92 @@ func (s *ValidatorService) Shutdown(ctx context.Context) error {
 
 // ============ Helpers ============
 
-func calculateSpeedKmH(lat1, lon1 float64, time1 time.Time, lat2, lon2 float64, time2 time.Time) float64 {
-	const R = 6371.0
-	dLat := toRad(lat2 - lat1)
-	dLon := toRad(lon2 - lon1)
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	distance := R * c
-	hours := time2.Sub(time1).Hours()
-	if hours <= 0 {
-		hours = 0.001
+// checkBuilder accumulates one CheckResult of the verdict breakdown.
+type checkBuilder struct {
+	*pb.CheckResult
+	source bool
+}
+
+func newCheck(check model.CheckName, value, threshold float64) checkBuilder {
+	return checkBuilder{CheckResult: &pb.CheckResult{
+		Check:     string(check),
+		Code:      string(model.CheckCodeOK),
+		Passed:    true,
+		Value:     value,
+		Threshold: threshold,
+	}}
+}
+
+// newSourceCheck starts a WiFi/Cell/BLE match check; Value counts matched
+// sources.
+func newSourceCheck(check model.CheckName) checkBuilder {
+	c := newCheck(check, 0, 0)
+	c.source = true
+	return c
+}
+
+func (c checkBuilder) fail(code model.CheckCode, message string) {
+	c.Code = string(code)
+	c.Passed = false
+	c.Message = message
+}
+
+func (c checkBuilder) match(contribution float32) {
+	c.Value++
+	c.Contribution += contribution
+}
+
+// result finalizes the check. A source check that never matched reports
+// SOURCE_UNKNOWN.
+func (c checkBuilder) result() *pb.CheckResult {
+	if c.source {
+		if c.Value == 0 {
+			c.Code = string(model.CheckCodeSourceUnknown)
+			c.Passed = false
+		} else {
+			c.Code = string(model.CheckCodeSourceMatched)
+		}
 	}
-	return distance / hours
+	return c.CheckResult
+}
+
+func toPBProfile(p *model.DeviceProfile) *pb.DeviceProfile {
+	return &pb.DeviceProfile{
+		Name:               p.Name,
+		Description:        p.Description,
+		MaxSpeedKmh:        p.MaxSpeedKmH,
+		MaxAccelerationMs2: p.MaxAccelerationMS2,
+		MaxTimeDiffSeconds: int64(p.MaxTimeDiff / time.Second),
+	}
+}
+
+func toPBGeofence(z *model.Geofence) (*pb.Geofence, error) {
+	geometry, err := core.EncodeGeometry(z.Polygons)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Geofence{
+		Id:             z.ID,
+		Name:           z.Name,
+		Kind:           string(z.Kind),
+		Penalty:        z.Penalty,
+		DevicePrefixes: z.DevicePrefixes,
+		Geometry:       string(geometry),
+	}, nil
 }
 
-func toRad(deg float64) float64 {
-	return deg * math.Pi / 180
+func toPBCorridor(c *model.Corridor) *pb.Corridor {
+	out := &pb.Corridor{
+		Id:           c.ID,
+		Name:         c.Name,
+		Mode:         c.Mode,
+		BufferMeters: c.BufferMeters,
+		MaxSpeedKmh:  c.MaxSpeedKmH,
+	}
+	for _, p := range c.Path {
+		out.Path = append(out.Path, &pb.GeoPoint{Latitude: p.Latitude, Longitude: p.Longitude})
+	}
+	return out
 }
 
 func (s *ValidatorService) recordWifiPointFromEGTS(ctx context.Context, bssid, ssid string, lat, lon float64, accuracy float32, rssi int32) {
@@ -410,16 +743,38 @@ func (s *ValidatorService) recordWifiPointFromEGTS(ctx context.Context, bssid, s
 	}
 }
 
-func (s *ValidatorService) recordCellPointFromEGTS(ctx context.Context, cellID, lac, mcc, mnc uint32, lat, lon float64, rssi int32) {
-	point := &cache.CellPoint{Lat: lat, Lon: lon, LastSeen: time.Now(), LAC: lac, MCC: mcc, MNC: mnc, EID: rssi}
-	if err := s.cache.SetCellPoint(ctx, cellID, lac, point); err != nil {
+func (s *ValidatorService) recordCellPointFromEGTS(ctx context.Context, key model.CellKey, lat, lon float64, rssi int32) {
+	point := &cache.CellPoint{Lat: lat, Lon: lon, LastSeen: time.Now(), LAC: key.LAC, MCC: key.MCC, MNC: key.MNC, EID: rssi}
+	if err := s.cache.SetCellPoint(ctx, key, point); err != nil {
 		fmt.Printf("Warning: failed to save cell point: %v\n", err)
 	}
-	if err := s.storage.UpdatePointStats(ctx, fmt.Sprintf("cell_%d_%d", mcc, mnc), fmt.Sprintf("%d:%d", cellID, lac), lat, lon, 0); err != nil {
+	if err := s.storage.UpdatePointStats(ctx, "cell", key.String(), lat, lon, 0); err != nil {
 		fmt.Printf("Warning: failed to update cell stats: %v\n", err)
 	}
 }
 
+// cellIDsFromPB returns the global identities of reported cells as stored in
+// the validation history.
+func cellIDsFromPB(cells []*pb.CellTower) []string {
+	if len(cells) == 0 {
+		return nil
+	}
+	ids := make([]string, len(cells))
+	for i, c := range cells {
+		ids[i] = cellKeyFromPB(c).String()
+	}
+	return ids
+}
+
+// cellKeyFromPB returns the global identity of a reported cell.
+func cellKeyFromPB(c *pb.CellTower) model.CellKey {
+	key := model.CellKey{MCC: c.Mcc, MNC: c.Mnc, LAC: c.Lac, CellID: c.CellId}
+	if c.Radio != pb.RadioType_RADIO_TYPE_UNSPECIFIED {
+		key.Radio = model.RadioType(c.Radio.String())
+	}
+	return key
+}
+
 func (s *ValidatorService) saveToHistory(ctx context.Context, req *pb.CoordinateRequest, result pb.ValidationResult, confidence float32) {
 	resultStr := "valid"
 	switch result {
@@ -437,6 +792,7 @@ func (s *ValidatorService) saveToHistory(ctx context.Context, req *pb.Coordinate
 		HasWifi:          len(req.Wifi) > 0,
 		HasBluetooth:     len(req.Bluetooth) > 0,
 		HasCell:          len(req.CellTowers) > 0,
+		CellIDs:          cellIDsFromPB(req.CellTowers),
 		ValidationResult: resultStr,
 		Confidence:       confidence,
 	}
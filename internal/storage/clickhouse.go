@@ -69,6 +69,7 @@ func (s *ClickHouseStorage) createTables(ctx context.Context) error {
 			has_wift Bool,
 			has_bt Bool,
 			has_cell Bool,
+			cell_ids Array(String), -- radio:mcc:mnc:lac:cell_id
 			result String,
 			confidence Float32,
 			flow_type String,
@@ -76,9 +77,12 @@ func (s *ClickHouseStorage) createTables(ctx context.Context) error {
 		) ENGINE = MergeTree()
 		ORDER BY (device_id, timestamp)`,
 
+		// Tables created before global cell identities
+		`ALTER TABLE validation_requests ADD COLUMN IF NOT EXISTS cell_ids Array(String) AFTER has_cell`,
+
 		`CREATE TABLE IF NOT EXISTS source_stats (
 			type String,
-			point_id String,
+			point_id String, -- BSSID, MAC or radio:mcc:mnc:lac:cell_id
 			latitude Float64,
 			longitude Float64,
 			observations Int64,
@@ -172,8 +176,8 @@ func (s *ClickHouseStorage) flushLocked() {
 	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO validation_requests (
 			device_id, latitude, longitude, accuracy, timestamp,
-			has_wift, has_bt, has_cell, result, confidence, flow_type, insert_time
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			has_wift, has_bt, has_cell, cell_ids, result, confidence, flow_type, insert_time
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		log.Printf("[Storage] Failed to prepare: %v", err)
@@ -185,7 +189,7 @@ func (s *ClickHouseStorage) flushLocked() {
 	for _, r := range records {
 		_, err := stmt.ExecContext(ctx,
 			r.DeviceID, r.Latitude, r.Longitude, r.Accuracy, r.Timestamp,
-			r.HasWifi, r.HasBT, r.HasCell, string(r.Result), r.Confidence,
+			r.HasWifi, r.HasBT, r.HasCell, r.CellIDs, string(r.Result), r.Confidence,
 			r.FlowType, r.InsertTime,
 		)
 		if err != nil {
@@ -208,11 +212,11 @@ func (s *ClickHouseStorage) InsertValidationSync(ctx context.Context, record mod
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO validation_requests (
 			device_id, latitude, longitude, accuracy, timestamp,
-			has_wift, has_bt, has_cell, result, confidence, flow_type, insert_time
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			has_wift, has_bt, has_cell, cell_ids, result, confidence, flow_type, insert_time
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		record.DeviceID, record.Latitude, record.Longitude, record.Accuracy, record.Timestamp,
-		record.HasWifi, record.HasBT, record.HasCell, string(record.Result), record.Confidence,
+		record.HasWifi, record.HasBT, record.HasCell, record.CellIDs, string(record.Result), record.Confidence,
 		record.FlowType, record.InsertTime,
 	)
 